@@ -1,58 +1,384 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 
 	"github.com/sftsrv/lynks/config"
 	"github.com/sftsrv/lynks/files"
+	"github.com/sftsrv/lynks/remote"
 	"github.com/sftsrv/lynks/theme"
 
 	lg "github.com/charmbracelet/lipgloss"
 )
 
-func Lint(config config.Config, paths []files.RelativePath) {
+type LintOptions struct {
+	// Re-lint whenever files change
+	Watch bool
+	// Request remote links to check that they still exist
+	CheckRemote bool
+	// Only lint these files, directories or globs. Links are still resolved
+	// against all files
+	Files []string
+	// Only lint files with staged changes, using their staged contents
+	Staged bool
+	// Lint the contents of this reader as if they were in StdinFilename
+	Stdin         io.Reader
+	StdinFilename string
+}
+
+type linter struct {
+	config  config.Config
+	options LintOptions
+	checker *remote.Checker
+	filter  *fileFilter
+}
+
+func newLinter(config config.Config, options LintOptions) linter {
+	l := linter{config: config, options: options}
+
+	if options.CheckRemote {
+		cacheFile := ""
+		if config.Cache {
+			cacheFile = filepath.Join(config.Dir, files.CacheDir, "remote.json")
+		}
+
+		l.checker = remote.NewChecker(config.Remote, cacheFile)
+	}
+
+	return l
+}
+
+// Lint reports the problems with the links in the given files and returns the
+// exit code, which is only successful if no errors were found
+func Lint(config config.Config, paths []files.RelativePath, options LintOptions) int {
+	filter, err := newFileFilter(options.Files)
+	if err != nil {
+		return UsageError(err)
+	}
+
+	if options.Staged && options.Watch {
+		return UsageError(errors.New("--staged can't be used with --watch"))
+	}
+
+	l := newLinter(config, options)
+	l.filter = filter
+
+	if options.Stdin != nil && options.StdinFilename == "" {
+		return UsageError(errors.New("--stdin-filename is required when using --stdin"))
+	}
+
+	if options.Stdin != nil && (options.Watch || options.Staged || len(options.Files) > 0) {
+		return UsageError(errors.New("--stdin can't be used with --watch, --staged or files"))
+	}
+
+	if options.Stdin != nil {
+		index, err := stdinIndex(config, options)
+		if err != nil {
+			return RunError("Could not read stdin", err)
+		}
+
+		return l.exitCode(l.report(index))
+	}
+
+	if err := filter.check(paths); err != nil {
+		return UsageError(err)
+	}
+
+	if options.Staged {
+		index, err := stagedIndex(config, filter.apply(paths))
+		if err != nil {
+			return RunError("Could not read staged files", err)
+		}
+
+		return l.exitCode(l.report(index))
+	}
+
+	index := files.NewIndex(config, filter.apply(paths))
+
+	if options.Watch {
+		return l.watch(index)
+	}
+
+	return l.exitCode(l.report(index))
+}
+
+// An index of the contents read from stdin. The file doesn't need to exist,
+// e.g. for an unsaved buffer in an editor
+func stdinIndex(c config.Config, options LintOptions) (*files.Index, error) {
+	contents, err := io.ReadAll(options.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	// paths are relative to the current directory everywhere else
+	p := filepath.Clean(options.StdinFilename)
+	if filepath.IsAbs(p) {
+		if cwd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(cwd, p); err == nil {
+				p = rel
+			}
+		}
+	}
+
+	parsed := files.ParseFiles(c, map[files.RelativePath]string{files.RelativePath(p): string(contents)})
+	return files.NewIndexOf(c, parsed), nil
+}
+
+func (l linter) exitCode(errorCount int) int {
+	if errorCount > 0 {
+		return errorExitCode
+	}
+
+	return successExitCode
+}
+
+// Re-lints whenever a file in the root changes. Only the changed files and the
+// files linking to them are re-read
+func (l linter) watch(index *files.Index) int {
+	watcher, err := files.NewWatcher(l.config)
+	if err != nil {
+		fmt.Println(theme.Alert.Render(fmt.Sprintf("Failed to watch %s: %v", l.config.Root, err)))
+		return errorExitCode
+	}
+
+	defer watcher.Close()
+
+	l.watchReport(index)
+	for {
+		select {
+		case changes, ok := <-watcher.Changes:
+			if !ok {
+				return successExitCode
+			}
+
+			index.Update(changes)
+			l.watchReport(index)
+
+		case err := <-watcher.Errors:
+			fmt.Println(theme.Alert.Render(fmt.Sprintf("Watch error: %v", err)))
+		}
+	}
+}
+
+func (l linter) watchReport(index *files.Index) {
+	// clear the screen and move the cursor to the top so the report replaces the previous one
+	fmt.Print("\033[H\033[2J")
+	l.report(index)
+	fmt.Println(theme.Faded.Render("Watching for changes, <ctrl+c> to exit"))
+}
+
+func (l linter) checkRemote(index *files.Index) map[string]remote.Result {
+	if l.checker == nil {
+		return map[string]remote.Result{}
+	}
+
+	urls := []string{}
+	for _, path := range index.Paths() {
+		_, links, _ := index.Get(path)
+		for _, link := range links {
+			if link.IsRemote() {
+				urls = append(urls, string(link.Resolved))
+			}
+		}
+	}
+
+	return l.checker.Check(urls)
+}
+
+// A lint rule and the problems it found
+type category struct {
+	heading  string
+	severity func(config.Rules) config.Severity
+	summary  string
+	result   string
+	remote   bool
+	links    []string
+	count    int
+}
+
+func categories() []*category {
+	return []*category{
+		{
+			heading:  "Unresolved links:",
+			severity: func(r config.Rules) config.Severity { return r.UnresolvedLinks },
+			summary:  "unresolved links found",
+			result:   "Found unresolved links",
+		},
+		{
+			heading:  "Remote links that should be local:",
+			severity: func(r config.Rules) config.Severity { return r.RemoteShouldBeLocal },
+			summary:  "remote links that should be local found",
+			result:   "Found remote links that should be local",
+		},
+		{
+			heading:  "Broken remote links:",
+			severity: func(r config.Rules) config.Severity { return r.BrokenRemoteLinks },
+			summary:  "broken remote links found",
+			result:   "Found broken remote links",
+			remote:   true,
+		},
+		{
+			heading:  "Redirected remote links:",
+			severity: func(r config.Rules) config.Severity { return r.RedirectedRemoteLinks },
+			summary:  "redirected remote links found",
+			result:   "Found redirected remote links",
+			remote:   true,
+		},
+		{
+			heading:  "Ambiguous aliases:",
+			severity: func(r config.Rules) config.Severity { return r.AmbiguousAliases },
+			summary:  "ambiguous aliases found",
+			result:   "Found ambiguous aliases",
+		},
+	}
+}
+
+// Problems with the config itself rather than with a file
+func (l linter) configProblems() []string {
+	problems := []string{}
+	for _, target := range l.config.Targets() {
+		problems = append(problems, target.AmbiguousAliases()...)
+	}
+
+	slices.Sort(problems)
+	return slices.Compact(problems)
+}
+
+// Prints the lint results for all files in the index and returns the number
+// of errors found. The severity of each rule depends on the file so problems
+// are only counted if their rule is not off for the file
+func (l linter) report(index *files.Index) int {
+	// files created while watching are indexed even if they aren't linted
+	paths := l.filter.apply(index.Paths())
+	results := l.checkRemote(index)
+
 	fileCount := len(paths)
 	linkCount := 0
-	unresolvedCount := 0
+	errorCount := 0
+	warningCount := 0
+
+	all := categories()
+	unresolved, local, broken, redirected, ambiguous := all[0], all[1], all[2], all[3], all[4]
+	errored := map[*category]bool{}
+
+	// counts the problems in each category and returns whether any were found
+	count := func(rules config.Rules) bool {
+		problems := false
+		for _, c := range all {
+			severity := c.severity(rules)
+			if severity == config.SeverityOff || len(c.links) == 0 {
+				c.links = nil
+				continue
+			}
+
+			problems = true
+			c.count += len(c.links)
+
+			if severity == config.SeverityError {
+				errorCount += len(c.links)
+				errored[c] = true
+			} else {
+				warningCount += len(c.links)
+			}
+		}
+
+		return problems
+	}
+
+	ambiguous.links = l.configProblems()
+	if count(l.config.Rules) {
+		fmt.Println(theme.Heading.Render("Config") + theme.Primary.MarginLeft(1).Render(configFile(l.config)))
+		printLinks(ambiguous.heading, ambiguous.severity(l.config.Rules), ambiguous.links)
+	}
 
 	for _, path := range paths {
-		file, links := files.ReadFile(config, path)
+		file, links, _ := index.Get(path)
 		linkCount += len(links)
 
-		if !file.HasUnresolvedLinks {
-			continue
+		rules := l.config.ForFile(string(path)).Rules
+		for _, c := range all {
+			c.links = []string{}
 		}
 
-		fmt.Println(theme.Heading.Render(string(file.Path)))
-		fmt.Println(theme.Faded.Render("Unresolved links:"))
 		for _, link := range links {
 			if link.IsUnresolved() {
-				unresolvedCount++
-				fmt.Println(theme.Warn.PaddingLeft(2).Render(link.Title()))
+				unresolved.links = append(unresolved.links, link.Title())
+			}
+
+			if link.ShouldBeLocal() {
+				local.links = append(local.links, link.Title())
+			}
+
+			result, ok := results[string(link.Resolved)]
+			if !ok || !link.IsRemote() {
+				continue
+			}
+
+			if result.IsBroken() {
+				broken.links = append(broken.links, link.Title()+" "+result.Title())
+			} else if result.IsRedirected() {
+				redirected.links = append(redirected.links, link.Title()+" "+result.Title())
 			}
 		}
+
+		if !count(rules) {
+			continue
+		}
+
+		fmt.Println(theme.Heading.Render(string(file.Path)))
+		for _, c := range all {
+			printLinks(c.heading, c.severity(rules), c.links)
+		}
 	}
 
 	result := theme.Heading.Render("No unresolved links!")
-	if unresolvedCount > 0 {
-		result = theme.Alert.Render("Found unresolved links")
+	for _, c := range all {
+		if errored[c] {
+			result = theme.Alert.Render(c.result)
+			break
+		}
+	}
+
+	summary := []string{
+		theme.Heading.Render("Summary"),
+		theme.Primary.Render(fmt.Sprintf("%d files checked", fileCount)),
+		theme.Primary.Render(fmt.Sprintf("%d links checked", linkCount)),
+	}
+
+	for _, c := range all {
+		if !c.remote || l.checker != nil {
+			summary = append(summary, theme.Primary.Render(fmt.Sprintf("%d %s", c.count, c.summary)))
+		}
 	}
 
+	summary = append(summary, theme.Primary.Render(fmt.Sprintf("%d errors, %d warnings", errorCount, warningCount)))
+	summary = append(summary, lg.NewStyle().MarginTop(1).Render(result))
+
 	fmt.Println(
 		lg.NewStyle().Padding(1, 2).Border(lg.NormalBorder()).Render(
-			lg.JoinVertical(lg.Top,
-				theme.Heading.Render("Summary"),
-				theme.Primary.Render(fmt.Sprintf("%d files checked", fileCount)),
-				theme.Primary.Render(fmt.Sprintf("%d links checked", linkCount)),
-				theme.Primary.Render(fmt.Sprintf("%d unresolved links found", unresolvedCount)),
-				lg.NewStyle().MarginTop(1).Render(result),
-			),
+			lg.JoinVertical(lg.Top, summary...),
 		))
 
-	if unresolvedCount > 0 {
-		os.Exit(1)
+	return errorCount
+}
+
+func printLinks(heading string, severity config.Severity, links []string) {
+	if len(links) == 0 {
+		return
 	}
 
-	os.Exit(0)
+	style := theme.Warn
+	if severity == config.SeverityError {
+		style = theme.Error
+	}
+
+	fmt.Println(theme.Faded.Render(heading) + " " + style.Render(string(severity)))
+	for _, link := range links {
+		fmt.Println(style.PaddingLeft(2).Render(link))
+	}
 }
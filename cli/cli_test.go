@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func TestLintStdin(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"lynks.config.json": `{ "overrides": [{ "files": "drafts", "rules": { "unresolvedLinks": "warn" } }] }`,
+		"docs/a.md":         "See [missing](docs/missing.md)",
+		"docs/b.md":         "# B",
+	})
+
+	c, err := config.Load("lynks.config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := files.GetMarkdownFiles(c)
+	cwd, _ := os.Getwd()
+
+	type Case struct {
+		contents string
+		filename string
+		expected int
+	}
+
+	cases := []Case{
+		// the broken file on disk isn't checked
+		{"See [b](docs/b.md)", "docs/a.md", successExitCode},
+		{"See [b](../b.md)", "docs/unsaved/new.md", successExitCode},
+		{"See [missing](docs/missing.md)", "docs/new.md", errorExitCode},
+		{"See [missing](docs/missing.md)", "drafts/new.md", successExitCode},
+		{"See [missing](docs/missing.md)", filepath.Join(cwd, "drafts/new.md"), successExitCode},
+		{"See [b](docs/b.md)", "", usageErrorExitCode},
+	}
+
+	for _, c2 := range cases {
+		result := Lint(c, paths, LintOptions{Stdin: strings.NewReader(c2.contents), StdinFilename: c2.filename})
+		if result != c2.expected {
+			t.Errorf("\ngiven %v %v\ngot %v\nexpected %v", c2.contents, c2.filename, result, c2.expected)
+		}
+	}
+
+	result := Lint(c, paths, LintOptions{Stdin: strings.NewReader(""), StdinFilename: "docs/a.md", Files: []string{"docs"}})
+	if result != usageErrorExitCode {
+		t.Errorf("expected --stdin with files to be a usage error, got %v", result)
+	}
+}
@@ -0,0 +1,643 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+	"github.com/sftsrv/lynks/lsp"
+	"github.com/sftsrv/lynks/theme"
+	"github.com/sftsrv/lynks/ui"
+	"github.com/sftsrv/lynks/wizard"
+)
+
+// Version is set when building a release using
+// `-ldflags "-X github.com/sftsrv/lynks/cli.Version=v1.2.3"`
+var Version = ""
+
+func version() string {
+	if Version != "" {
+		return Version
+	}
+
+	// set when installed using `go install github.com/sftsrv/lynks@version`
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	return "dev"
+}
+
+// Flags that are accepted by every command
+type globalOptions struct {
+	config    string
+	workspace string
+}
+
+func (g *globalOptions) add(f *flag.FlagSet) {
+	f.StringVar(&g.config, "config", g.config, "path to the config file, by default the current directory and its parents are searched")
+	f.StringVar(&g.workspace, "workspace", g.workspace, "only check the workspace with this name or root, by default all workspaces are checked")
+}
+
+// Everything a command needs to run
+type context struct {
+	global *globalOptions
+	// only loaded for commands that need it
+	config config.Config
+	// names of the command and its parents, e.g. `lynks config show`
+	path []string
+}
+
+type command struct {
+	name    string
+	summary string
+	// positional arguments shown in the usage, commands without any don't
+	// accept arguments
+	args string
+	// adds the command's own flags
+	flags func(f *flag.FlagSet)
+	// whether the config is loaded before the command is run
+	needsConfig bool
+	// commands that are only used by other tools, e.g. by shell completion,
+	// aren't shown in the help
+	hidden bool
+	// the arguments are passed to run as they are without parsing flags
+	rawArgs bool
+	// candidates for the argument being typed, for shell completion
+	complete func(ctx context, args []string, word string) []string
+	run      func(ctx context, args []string) int
+	commands []*command
+}
+
+func (c *command) find(name string) *command {
+	for _, sub := range c.commands {
+		if sub.name == name {
+			return sub
+		}
+	}
+
+	return nil
+}
+
+// Names of the commands that are shown in the help
+func (c *command) names() []string {
+	names := []string{}
+	for _, sub := range c.visible() {
+		names = append(names, sub.name)
+	}
+
+	return names
+}
+
+func (c *command) visible() []*command {
+	visible := []*command{}
+	for _, sub := range c.commands {
+		if !sub.hidden {
+			visible = append(visible, sub)
+		}
+	}
+
+	return visible
+}
+
+// Run runs the command given by the arguments, not including the program
+// name, and returns the exit code
+func Run(args []string) int {
+	global := &globalOptions{}
+	return commands().execute(context{global: global, path: []string{"lynks"}}, args)
+}
+
+func commands() *command {
+	lintOptions := LintOptions{}
+	showVersion := false
+	forceHook := false
+	lintStdin := false
+	fixOptions := FixOptions{}
+	moveDryRun := false
+	graphFormat := ""
+
+	tui := func(ctx context, args []string) int {
+		if showVersion {
+			fmt.Println(version())
+			return successExitCode
+		}
+
+		// loaded here so that --version works without a valid config
+		config, code := loadConfig(ctx.global)
+		if code != successExitCode {
+			return code
+		}
+
+		err := ui.Run(config, files.GetMarkdownFiles(config))
+		if err != nil {
+			return RunError("Alas, there's been an error", err)
+		}
+
+		return successExitCode
+	}
+
+	root := &command{
+		name:    "lynks",
+		summary: "A CLI tool for interactively fixing links in markdown files, runs the interactive mode if no command is given",
+		flags: func(f *flag.FlagSet) {
+			f.BoolVar(&showVersion, "version", false, "print the version and exit")
+		},
+		run: tui,
+		commands: []*command{
+			{
+				name:    "tui",
+				summary: "Browse the markdown files and fix their links interactively",
+				run:     tui,
+			},
+			{
+				name:        "lint",
+				summary:     "Check the links in the markdown files, or only the given files, directories or globs. Exits with 1 if any errors are found",
+				args:        "[files...]",
+				needsConfig: true,
+				flags: func(f *flag.FlagSet) {
+					f.BoolVar(&lintOptions.Watch, "watch", false, "re-lint whenever files change")
+					f.BoolVar(&lintOptions.CheckRemote, "check-remote", false, "check that remote links can be reached")
+					f.BoolVar(&lintOptions.Staged, "staged", false, "only lint files with staged changes, using the contents staged in git")
+					f.BoolVar(&lintStdin, "stdin", false, "lint markdown read from stdin, e.g. an unsaved file in an editor")
+					f.StringVar(&lintOptions.StdinFilename, "stdin-filename", "", "path of the file that the markdown read from stdin is in, used to resolve its links")
+				},
+				complete: func(ctx context, args []string, word string) []string {
+					return markdownCompletions(ctx, word)
+				},
+				run: func(ctx context, args []string) int {
+					lintOptions.Files = args
+					if lintStdin {
+						lintOptions.Stdin = os.Stdin
+					}
+
+					return Lint(ctx.config, files.GetMarkdownFiles(ctx.config), lintOptions)
+				},
+			},
+			{
+				name:        "fix",
+				summary:     "Fix the links that don't need a choice to be made, in all files or only the given files, directories or globs. Exits with 1 if any unresolved links are left",
+				args:        "[files...]",
+				needsConfig: true,
+				flags: func(f *flag.FlagSet) {
+					f.BoolVar(&fixOptions.DryRun, "dry-run", false, "print the fixes without changing any files")
+				},
+				complete: func(ctx context, args []string, word string) []string {
+					return markdownCompletions(ctx, word)
+				},
+				run: func(ctx context, args []string) int {
+					fixOptions.Files = args
+					return Fix(ctx.config, files.GetMarkdownFiles(ctx.config), fixOptions)
+				},
+			},
+			{
+				name:        "mv",
+				summary:     "Move a markdown file and update the links to it and in it",
+				args:        "<file> <destination>",
+				needsConfig: true,
+				flags: func(f *flag.FlagSet) {
+					f.BoolVar(&moveDryRun, "dry-run", false, "print the changes without moving or changing any files")
+				},
+				complete: func(ctx context, args []string, word string) []string {
+					switch len(args) {
+					case 0:
+						return markdownCompletions(ctx, word)
+					case 1:
+						return pathCompletions(word)
+					}
+
+					return nil
+				},
+				run: func(ctx context, args []string) int {
+					if len(args) != 2 {
+						return usageError(ctx.path, errors.New("expected a file and a destination"))
+					}
+
+					return Move(ctx.config, files.GetMarkdownFiles(ctx.config), args[0], args[1], moveDryRun)
+				},
+			},
+			{
+				name:        "graph",
+				summary:     "Print the links between the markdown files as text, a Graphviz dot graph or JSON",
+				needsConfig: true,
+				flags: func(f *flag.FlagSet) {
+					f.StringVar(&graphFormat, "format", "text", "output format, one of "+strings.Join(graphFormats, ", "))
+				},
+				run: func(ctx context, args []string) int {
+					return Graph(ctx.config, files.GetMarkdownFiles(ctx.config), graphFormat)
+				},
+			},
+			{
+				name:        "backlinks",
+				summary:     "Print the files that link to a file",
+				args:        "<file>",
+				needsConfig: true,
+				complete: func(ctx context, args []string, word string) []string {
+					if len(args) > 0 {
+						return nil
+					}
+
+					return markdownCompletions(ctx, word)
+				},
+				run: func(ctx context, args []string) int {
+					if len(args) != 1 {
+						return usageError(ctx.path, errors.New("expected a file"))
+					}
+
+					return Backlinks(ctx.config, files.GetMarkdownFiles(ctx.config), args[0])
+				},
+			},
+			{
+				name:    "lsp",
+				summary: "Run a language server over stdio for editors to show link problems, follow links and complete paths",
+				run: func(ctx context, args []string) int {
+					// stdout is used to talk to the editor so problems are written to stderr
+					c, err := readConfig(ctx.global)
+					if err != nil {
+						fmt.Fprintln(os.Stderr, "Invalid config:", err)
+						return configErrorExitCode
+					}
+
+					c, err = c.Select(ctx.global.workspace)
+					if err != nil {
+						fmt.Fprintln(os.Stderr, "Invalid usage:", err)
+						return usageErrorExitCode
+					}
+
+					if err := lsp.Serve(c, os.Stdin, os.Stdout); err != nil {
+						fmt.Fprintln(os.Stderr, "Language server stopped:", err)
+						return errorExitCode
+					}
+
+					return successExitCode
+				},
+			},
+			{
+				name:    "init",
+				summary: "Create a config based on how the markdown in the current directory is written",
+				run: func(ctx context, args []string) int {
+					if err := wizard.Run(); err != nil {
+						return RunError("Could not create config", err)
+					}
+
+					return successExitCode
+				},
+			},
+			{
+				name:    "config",
+				summary: "Inspect the config that lynks is using",
+				commands: []*command{
+					{
+						name:        "show",
+						summary:     "Print the effective config and where each value came from",
+						needsConfig: true,
+						run: func(ctx context, args []string) int {
+							ConfigShow(ctx.config)
+							return successExitCode
+						},
+					},
+					{
+						name:        "validate",
+						summary:     "Check the config for problems, exits with 2 if any are found",
+						needsConfig: true,
+						run: func(ctx context, args []string) int {
+							ConfigValidate(ctx.config)
+							return successExitCode
+						},
+					},
+					{
+						name:    "schema",
+						summary: "Print the JSON Schema for the config",
+						run: func(ctx context, args []string) int {
+							if err := ConfigSchema(); err != nil {
+								return RunError("Could not create schema", err)
+							}
+
+							return successExitCode
+						},
+					},
+				},
+			},
+			{
+				name:    "hook",
+				summary: "Manage the git hooks that run lynks",
+				commands: []*command{
+					{
+						name:    "install",
+						summary: "Install a git pre-commit hook that lints the staged markdown files",
+						flags: func(f *flag.FlagSet) {
+							f.BoolVar(&forceHook, "force", false, "replace an existing pre-commit hook")
+						},
+						run: func(ctx context, args []string) int {
+							return HookInstall(forceHook)
+						},
+					},
+				},
+			},
+			{
+				name:    "version",
+				summary: "Print the version",
+				run: func(ctx context, args []string) int {
+					fmt.Println(version())
+					return successExitCode
+				},
+			},
+		},
+	}
+
+	root.commands = append(root.commands, &command{
+		name:    "help",
+		summary: "Show the help for a command",
+		args:    "[command...]",
+		complete: func(ctx context, args []string, word string) []string {
+			c := root
+			for _, name := range args {
+				if c = c.find(name); c == nil {
+					return nil
+				}
+			}
+
+			return withPrefix(c.names(), word)
+		},
+		run: func(ctx context, args []string) int {
+			c := root
+			path := []string{root.name}
+
+			for _, name := range args {
+				sub := c.find(name)
+				if sub == nil {
+					return usageError(ctx.path, unknownCommand(c, path, name))
+				}
+
+				c = sub
+				path = append(path, sub.name)
+			}
+
+			c.printHelp(path)
+			return successExitCode
+		},
+	})
+
+	root.commands = append(root.commands, completionCommand(), completeCommand(root))
+	return root
+}
+
+// Parses the flags of a command without subcommands. Flags can be given
+// before or after the arguments, anything after `--` is an argument
+func parseInterspersed(f *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+
+	for {
+		err := f.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+
+		rest := f.Args()
+		consumed := len(args) - len(rest)
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// Flag sets report errors by returning them rather than printing and exiting
+func newFlagSet(name string) *flag.FlagSet {
+	f := flag.NewFlagSet(name, flag.ContinueOnError)
+	f.SetOutput(io.Discard)
+	return f
+}
+
+func (c *command) execute(ctx context, args []string) int {
+	if c.rawArgs {
+		return c.run(ctx, args)
+	}
+
+	f := newFlagSet(strings.Join(ctx.path, " "))
+
+	ctx.global.add(f)
+	if c.flags != nil {
+		c.flags(f)
+	}
+
+	// flags for commands with subcommands come before the subcommand
+	var positional []string
+	var err error
+	if len(c.commands) > 0 {
+		err = f.Parse(args)
+		positional = f.Args()
+	} else {
+		positional, err = parseInterspersed(f, args)
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		c.printHelp(ctx.path)
+		return successExitCode
+	}
+
+	if err != nil {
+		return usageError(ctx.path, err)
+	}
+
+	if len(c.commands) > 0 && len(positional) > 0 {
+		sub := c.find(positional[0])
+		if sub == nil {
+			return usageError(ctx.path, unknownCommand(c, ctx.path, positional[0]))
+		}
+
+		ctx.path = append(slices.Clone(ctx.path), sub.name)
+		return sub.execute(ctx, positional[1:])
+	}
+
+	if c.run == nil {
+		return usageError(ctx.path, fmt.Errorf("missing command, expected one of %s", strings.Join(c.names(), ", ")))
+	}
+
+	if c.args == "" && len(positional) > 0 {
+		return usageError(ctx.path, fmt.Errorf("unexpected argument %q", positional[0]))
+	}
+
+	if c.needsConfig {
+		config, code := loadConfig(ctx.global)
+		if code != successExitCode {
+			return code
+		}
+
+		ctx.config = config
+	}
+
+	return c.run(ctx, positional)
+}
+
+// Finds and reads the config given by the flags
+func readConfig(global *globalOptions) (config.Config, error) {
+	path := global.config
+	if path == "" {
+		discovered, err := config.Discover(".")
+		if err != nil {
+			return config.Config{}, err
+		}
+
+		path = discovered
+	}
+
+	// not having a config is fine, the defaults are used instead
+	return config.Load(path)
+}
+
+// Finds and loads the config, selecting the workspace if one was given
+func loadConfig(global *globalOptions) (config.Config, int) {
+	c, err := readConfig(global)
+	if err != nil {
+		return c, ConfigError(err)
+	}
+
+	c, err = c.Select(global.workspace)
+	if err != nil {
+		return c, UsageError(err)
+	}
+
+	return c, successExitCode
+}
+
+func usageError(path []string, err error) int {
+	code := UsageError(err)
+	fmt.Fprintln(os.Stderr, theme.Faded.Render(fmt.Sprintf("Run `%s --help` for usage", strings.Join(path, " "))))
+	return code
+}
+
+func unknownCommand(c *command, path []string, name string) error {
+	if suggestion := suggest(name, c.names()); suggestion != "" {
+		return fmt.Errorf("unknown command %q for %s, did you mean %q?", name, strings.Join(path, " "), suggestion)
+	}
+
+	return fmt.Errorf("unknown command %q for %s, expected one of %s", name, strings.Join(path, " "), strings.Join(c.names(), ", "))
+}
+
+// Number of single character edits needed to change a into b
+func distance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+// The closest name to a mistyped one, empty if none are close enough
+func suggest(name string, names []string) string {
+	best := ""
+	bestDistance := 3
+
+	for _, candidate := range names {
+		if d := distance(name, candidate); d < bestDistance {
+			best = candidate
+			bestDistance = d
+		}
+	}
+
+	return best
+}
+
+func flagUsages(f *flag.FlagSet) []string {
+	names := []string{}
+	usages := []string{}
+
+	f.VisitAll(func(fl *flag.Flag) {
+		kind, usage := flag.UnquoteUsage(fl)
+		names = append(names, strings.TrimSpace("--"+fl.Name+" "+kind))
+		usages = append(usages, usage)
+	})
+
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+
+	lines := []string{}
+	for i, name := range names {
+		lines = append(lines, fmt.Sprintf("  %-*s  %s", width, name, usages[i]))
+	}
+
+	return lines
+}
+
+func (c *command) printHelp(path []string) {
+	usage := strings.Join(path, " ")
+	if len(c.commands) > 0 {
+		usage += " [command]"
+	}
+
+	if c.args != "" {
+		usage += " " + c.args
+	}
+
+	fmt.Println(c.summary)
+	fmt.Println()
+	fmt.Println(theme.Heading.Render("Usage"))
+	fmt.Println("  " + usage + " [flags]")
+
+	if len(c.commands) > 0 {
+		width := 0
+		for _, sub := range c.visible() {
+			width = max(width, len(sub.name))
+		}
+
+		fmt.Println()
+		fmt.Println(theme.Heading.Render("Commands"))
+		for _, sub := range c.visible() {
+			fmt.Printf("  %-*s  %s\n", width, sub.name, sub.summary)
+		}
+	}
+
+	if c.flags != nil {
+		own := newFlagSet(usage)
+		c.flags(own)
+
+		fmt.Println()
+		fmt.Println(theme.Heading.Render("Flags"))
+		fmt.Println(strings.Join(flagUsages(own), "\n"))
+	}
+
+	globals := newFlagSet(usage)
+	(&globalOptions{}).add(globals)
+	globals.Bool("help", false, "show the help for the command")
+
+	fmt.Println()
+	fmt.Println(theme.Heading.Render("Global flags"))
+	fmt.Println(strings.Join(flagUsages(globals), "\n"))
+
+	if len(c.commands) > 0 {
+		fmt.Println()
+		fmt.Println(theme.Faded.Render(fmt.Sprintf("Run `%s [command] --help` for more information about a command", strings.Join(path, " "))))
+	}
+}
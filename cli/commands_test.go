@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func TestExitCodes(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"lynks.config.json":            `{ "root": "./docs", "workspaces": [{ "name": "docs", "root": "./docs" }] }`,
+		"docs/a.md":                    "See [b](./b.md)",
+		"docs/b.md":                    "# B",
+		"broken/lynks.config.json":     `{ "rot": "./" }`,
+		"unresolved/lynks.config.json": `{}`,
+		"unresolved/a.md":              "See [missing](./missing.md)",
+	})
+
+	type Case struct {
+		args     string
+		expected int
+	}
+
+	cases := []Case{
+		{"--help", successExitCode},
+		{"-h", successExitCode},
+		{"--version", successExitCode},
+		{"version", successExitCode},
+		{"help", successExitCode},
+		{"help config show", successExitCode},
+		{"lint --help", successExitCode},
+		{"config --help", successExitCode},
+		{"lint", successExitCode},
+		{"lint --workspace docs", successExitCode},
+		{"lint docs/a.md --watch=false", successExitCode},
+		{"--workspace docs lint", successExitCode},
+		{"config validate", successExitCode},
+		{"config show", successExitCode},
+		{"config schema", successExitCode},
+		{"graph --format dot", successExitCode},
+		{"backlinks docs/b.md", successExitCode},
+		{"fix --dry-run", successExitCode},
+		{"mv docs/a.md docs/c.md --dry-run", successExitCode},
+
+		{"--config unresolved/lynks.config.json lint", errorExitCode},
+		{"lint --config unresolved/lynks.config.json", errorExitCode},
+
+		{"--config broken/lynks.config.json lint", configErrorExitCode},
+		{"config validate --config broken/lynks.config.json", configErrorExitCode},
+		{"--config missing.json lint", configErrorExitCode},
+
+		{"lnt", usageErrorExitCode},
+		{"lint --fast", usageErrorExitCode},
+		{"lint '[docs'", usageErrorExitCode},
+		{"config", usageErrorExitCode},
+		{"config shwo", usageErrorExitCode},
+		{"help nope", usageErrorExitCode},
+		{"--workspace nope lint", usageErrorExitCode},
+		{"version extra", usageErrorExitCode},
+		{"graph --format svg", usageErrorExitCode},
+		{"backlinks", usageErrorExitCode},
+		{"mv docs/a.md", usageErrorExitCode},
+		{"mv docs/a.md docs/b.md", usageErrorExitCode},
+		{"mv docs/missing.md docs/c.md", usageErrorExitCode},
+	}
+
+	for _, c := range cases {
+		result := Run(strings.Fields(c.args))
+		if result != c.expected {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.args, result, c.expected)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	names := []string{"tui", "lint", "init", "config", "version", "help"}
+
+	type Case struct {
+		name     string
+		expected string
+	}
+
+	cases := []Case{
+		{"lnt", "lint"},
+		{"lints", "lint"},
+		{"confg", "config"},
+		{"verison", "version"},
+		{"graph", ""},
+	}
+
+	for _, c := range cases {
+		result := suggest(c.name, names)
+		if result != c.expected {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.name, result, c.expected)
+		}
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	type Case struct {
+		args       string
+		positional string
+		watch      bool
+	}
+
+	cases := []Case{
+		{"a.md --watch b.md", "a.md b.md", true},
+		{"--watch a.md", "a.md", true},
+		{"a.md -- --watch", "a.md --watch", false},
+		{"", "", false},
+	}
+
+	for _, c := range cases {
+		options := LintOptions{}
+		f := newFlagSet("lint")
+		f.BoolVar(&options.Watch, "watch", false, "")
+
+		result, err := parseInterspersed(f, strings.Fields(c.args))
+		if err != nil || strings.Join(result, " ") != c.positional || options.Watch != c.watch {
+			t.Errorf("\ngiven %v\ngot %v %v %v\nexpected %v %v", c.args, result, options.Watch, err, c.positional, c.watch)
+		}
+	}
+}
+
+// Runs lynks and returns what it wrote to stdout and stderr
+func capture(t *testing.T, args []string) (string, string) {
+	t.Helper()
+
+	stdout, stderr := os.Stdout, os.Stderr
+	t.Cleanup(func() { os.Stdout, os.Stderr = stdout, stderr })
+
+	outRead, outWrite, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errRead, errWrite, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Stdout, os.Stderr = outWrite, errWrite
+	Run(args)
+	outWrite.Close()
+	errWrite.Close()
+
+	out, _ := io.ReadAll(outRead)
+	errOut, _ := io.ReadAll(errRead)
+
+	return string(out), string(errOut)
+}
+
+func TestErrorsAreWrittenToStderr(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"a.md":                     "See [b](./b.md)",
+		"broken/lynks.config.json": `{ "rot": "./" }`,
+	})
+
+	cases := []string{
+		"graph --format svg",
+		"mv a.md",
+		"mv missing.md b.md",
+		"--config broken/lynks.config.json graph",
+	}
+
+	for _, c := range cases {
+		out, errOut := capture(t, strings.Fields(c))
+		if out != "" || errOut == "" {
+			t.Errorf("\ngiven %v\ngot stdout %q and stderr %q\nexpected only stderr", c, out, errOut)
+		}
+	}
+}
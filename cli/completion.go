@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sftsrv/lynks/files"
+)
+
+// The scripts ask lynks for the candidates using the hidden __complete
+// command, which is given the words typed so far including the current one
+const bashCompletion = `# bash completion for lynks, add to ~/.bashrc:
+#   source <(lynks completion bash)
+_lynks() {
+    local IFS=$'\n'
+    COMPREPLY=($(lynks __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+
+    # don't add a space after directories so their contents can be completed
+    if [[ ${#COMPREPLY[@]} -eq 1 && ${COMPREPLY[0]} == */ ]]; then
+        compopt -o nospace
+    fi
+}
+
+complete -F _lynks lynks
+`
+
+const zshCompletion = `#compdef lynks
+# zsh completion for lynks, add to ~/.zshrc:
+#   source <(lynks completion zsh)
+# or save it as _lynks in a directory in your $fpath
+_lynks() {
+    local -a candidates dirs
+    candidates=("${(@f)$(lynks __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    candidates=(${candidates:#})
+
+    # don't add a space after directories so their contents can be completed
+    dirs=(${(M)candidates:#*/})
+    candidates=(${candidates:#*/})
+
+    compadd -S '' -- $dirs
+    compadd -- $candidates
+}
+
+if [ "$funcstack[1]" = "_lynks" ]; then
+    _lynks "$@"
+else
+    compdef _lynks lynks
+fi
+`
+
+const fishCompletion = `# fish completion for lynks, save to ~/.config/fish/completions/lynks.fish or run:
+#   lynks completion fish | source
+function __lynks_complete
+    set -l words (commandline -opc) (commandline -ct)
+    lynks __complete $words[2..-1] 2>/dev/null
+end
+
+complete -c lynks -f -a '(__lynks_complete)'
+`
+
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+func completionCommand() *command {
+	shells := slices.Sorted(maps.Keys(completionScripts))
+
+	return &command{
+		name:    "completion",
+		summary: "Print the shell completion script for bash, zsh or fish",
+		args:    "<shell>",
+		complete: func(ctx context, args []string, word string) []string {
+			if len(args) > 0 {
+				return nil
+			}
+
+			return withPrefix(shells, word)
+		},
+		run: func(ctx context, args []string) int {
+			if len(args) != 1 {
+				return usageError(ctx.path, fmt.Errorf("expected a shell, one of %s", strings.Join(shells, ", ")))
+			}
+
+			script, ok := completionScripts[args[0]]
+			if !ok {
+				return usageError(ctx.path, fmt.Errorf("unsupported shell %q, expected one of %s", args[0], strings.Join(shells, ", ")))
+			}
+
+			fmt.Print(script)
+			return successExitCode
+		},
+	}
+}
+
+func completeCommand(root *command) *command {
+	return &command{
+		name:    "__complete",
+		summary: "Print the completions for the words typed so far, used by the completion scripts",
+		hidden:  true,
+		rawArgs: true,
+		run: func(ctx context, args []string) int {
+			for _, candidate := range completions(root, ctx, args) {
+				fmt.Println(candidate)
+			}
+
+			return successExitCode
+		},
+	}
+}
+
+func withPrefix(candidates []string, prefix string) []string {
+	matching := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			matching = append(matching, candidate)
+		}
+	}
+
+	return matching
+}
+
+// Finds the candidates for the last of the arguments, which is the word that
+// is being typed and may be empty
+func completions(root *command, ctx context, args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+
+	word := args[len(args)-1]
+	c := root
+	positional := []string{}
+
+	// flags are parsed as they are found so that commands can complete using
+	// the config given by --config
+	f := newFlagSet(root.name)
+	ctx.global.add(f)
+
+	// the flag that the word is the value of
+	pending := ""
+
+	for _, arg := range args[:len(args)-1] {
+		if pending != "" {
+			f.Set(pending, arg)
+			pending = ""
+			continue
+		}
+
+		if strings.HasPrefix(arg, "-") && arg != "-" && arg != "--" {
+			name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			if hasValue {
+				f.Set(name, value)
+			} else if takesValue(c, name) {
+				pending = name
+			}
+
+			continue
+		}
+
+		if sub := c.find(arg); sub != nil && !sub.hidden && len(positional) == 0 {
+			c = sub
+			continue
+		}
+
+		positional = append(positional, arg)
+	}
+
+	switch {
+	case pending == "config":
+		return pathCompletions(word)
+
+	case pending == "workspace":
+		config, err := readConfig(ctx.global)
+		if err != nil {
+			return nil
+		}
+
+		return withPrefix(config.WorkspaceNames(), word)
+
+	case pending != "":
+		return nil
+
+	case strings.HasPrefix(word, "-"):
+		return withPrefix(flagNames(c), word)
+
+	case len(c.commands) > 0 && len(positional) == 0:
+		return withPrefix(c.names(), word)
+
+	case c.complete != nil:
+		return c.complete(ctx, positional, word)
+	}
+
+	return nil
+}
+
+// All the flags of a command, including the global flags
+func commandFlags(c *command) *flag.FlagSet {
+	f := newFlagSet(c.name)
+	(&globalOptions{}).add(f)
+	if c.flags != nil {
+		c.flags(f)
+	}
+
+	return f
+}
+
+func takesValue(c *command, name string) bool {
+	fl := commandFlags(c).Lookup(name)
+	if fl == nil {
+		return false
+	}
+
+	boolFlag, ok := fl.Value.(interface{ IsBoolFlag() bool })
+	return !ok || !boolFlag.IsBoolFlag()
+}
+
+func flagNames(c *command) []string {
+	names := []string{}
+	commandFlags(c).VisitAll(func(fl *flag.Flag) {
+		names = append(names, "--"+fl.Name)
+	})
+
+	return append(names, "--help")
+}
+
+// Files and directories starting with the word, directories end with a `/`
+func pathCompletions(word string) []string {
+	dir, prefix := filepath.Split(word)
+
+	entries, err := os.ReadDir(filepath.Join(".", dir))
+	if err != nil {
+		return nil
+	}
+
+	candidates := []string{}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+
+		candidate := dir + entry.Name()
+		if entry.IsDir() {
+			candidate += "/"
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates
+}
+
+// Markdown files that lynks checks which start with the word, for commands
+// that take files as arguments
+func markdownCompletions(ctx context, word string) []string {
+	config, err := readConfig(ctx.global)
+	if err != nil {
+		return nil
+	}
+
+	if selected, err := config.Select(ctx.global.workspace); err == nil {
+		config = selected
+	}
+
+	// paths are found without a leading `./` but it's common to type one
+	dot := ""
+	if strings.HasPrefix(word, "./") {
+		dot = "./"
+		word = strings.TrimPrefix(word, "./")
+	}
+
+	candidates := []string{}
+	for _, p := range files.GetMarkdownFiles(config) {
+		candidate := filepath.ToSlash(string(p))
+		if strings.HasPrefix(candidate, word) {
+			candidates = append(candidates, dot+candidate)
+		}
+	}
+
+	return candidates
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func TestCompletions(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"lynks.config.json": `{ "workspaces": [{ "name": "guides", "root": "./docs" }, { "root": "./blog" }] }`,
+		"docs/a.md":         "",
+		"docs/b.md":         "",
+		"blog/post.md":      "",
+		"other/lynks.json":  `{}`,
+	})
+
+	root := commands()
+
+	type Case struct {
+		args     []string
+		expected []string
+	}
+
+	cases := []Case{
+		{[]string{}, []string{"tui", "lint", "fix", "mv", "graph", "backlinks", "lsp", "init", "config", "hook", "version", "help", "completion"}},
+		{[]string{"li"}, []string{"lint"}},
+		{[]string{"config", ""}, []string{"show", "validate", "schema"}},
+		{[]string{"--config", "lynks.config.json", "config", "v"}, []string{"validate"}},
+		{[]string{"lint", "--w"}, []string{"--watch", "--workspace"}},
+		{[]string{"--v"}, []string{"--version"}},
+		{[]string{"--workspace", ""}, []string{"guides", "docs", "blog"}},
+		{[]string{"lint", "--workspace=guides", "--config", "o"}, []string{"other/"}},
+		{[]string{"--config", "other/"}, []string{"other/lynks.json"}},
+		{[]string{"help", "config", "s"}, []string{"show", "schema"}},
+		{[]string{"completion", "z"}, []string{"zsh"}},
+		{[]string{"lint", "--watch", "docs/"}, []string{"docs/a.md", "docs/b.md"}},
+		{[]string{"mv", "docs/"}, []string{"docs/a.md", "docs/b.md"}},
+		{[]string{"mv", "docs/a.md", "bl"}, []string{"blog/"}},
+		{[]string{"mv", "docs/a.md", "blog/", ""}, []string{}},
+		{[]string{"backlinks", "b"}, []string{"blog/post.md"}},
+		{[]string{"backlinks", "docs/a.md", ""}, []string{}},
+		{[]string{"fix", "--dry-run", "docs/"}, []string{"docs/a.md", "docs/b.md"}},
+		{[]string{"__complete", ""}, []string{}},
+	}
+
+	for _, c := range cases {
+		result := completions(root, context{global: &globalOptions{}}, c.args)
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.args, result, c.expected)
+		}
+	}
+}
+
+func TestMarkdownCompletions(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"lynks.config.json": `{ "workspaces": [{ "name": "guides", "root": "./docs" }, { "root": "./blog" }] }`,
+		"docs/a.md":         "",
+		"docs/nested/b.md":  "",
+		"blog/post.md":      "",
+		"notes.txt":         "",
+	})
+
+	type Case struct {
+		word      string
+		workspace string
+		expected  []string
+	}
+
+	cases := []Case{
+		{"", "", []string{"blog/post.md", "docs/a.md", "docs/nested/b.md"}},
+		{"docs/n", "", []string{"docs/nested/b.md"}},
+		{"./b", "", []string{"./blog/post.md"}},
+		{"", "guides", []string{"docs/a.md", "docs/nested/b.md"}},
+	}
+
+	for _, c := range cases {
+		ctx := context{global: &globalOptions{workspace: c.workspace}}
+
+		result := markdownCompletions(ctx, c.word)
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("\ngiven %v %v\ngot %v\nexpected %v", c.word, c.workspace, result, c.expected)
+		}
+	}
+}
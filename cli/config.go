@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/theme"
+)
+
+func configFile(config config.Config) string {
+	if config.File == "" {
+		return "no config found, using defaults"
+	}
+
+	return config.File
+}
+
+// Flattens the config into field paths and their json encoded values
+func configValues(value any, field string, values map[string]string) {
+	object, ok := value.(map[string]any)
+	if !ok || (len(object) == 0 && field != "") {
+		encoded, _ := json.Marshal(value)
+		values[field] = string(encoded)
+		return
+	}
+
+	for key, item := range object {
+		child := key
+		if field != "" {
+			child = field + "." + key
+		}
+
+		configValues(item, child, values)
+	}
+}
+
+// ConfigShow prints the effective config and where each value came from
+func ConfigShow(c config.Config) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+
+	decoded := map[string]any{}
+	json.Unmarshal(data, &decoded)
+
+	values := map[string]string{}
+	configValues(decoded, "", values)
+
+	fields := []string{}
+	width := 0
+	for field, value := range values {
+		fields = append(fields, field)
+		width = max(width, len(field)+len(value)+1)
+	}
+
+	slices.Sort(fields)
+
+	fmt.Println(theme.Heading.Render("Config") + theme.Primary.MarginLeft(1).Render(configFile(c)))
+	for _, field := range fields {
+		source, ok := c.Sources[field]
+		if !ok {
+			source = "default"
+		}
+
+		line := fmt.Sprintf("%s %s", theme.Primary.Render(field), values[field])
+		padding := width - len(field) - len(values[field])
+
+		fmt.Printf("%s%*s  %s\n", line, padding, "", theme.Faded.Render(source))
+	}
+}
+
+// ConfigValidate reports that the config is valid, invalid configs are
+// reported before commands are run
+func ConfigValidate(c config.Config) {
+	fmt.Println(theme.Heading.Render("Config is valid") + theme.Primary.MarginLeft(1).Render(configFile(c)))
+}
+
+// ConfigSchema prints the JSON Schema for the config
+func ConfigSchema() error {
+	schema, err := config.Schema()
+	if err != nil {
+		return err
+	}
+
+	os.Stdout.Write(schema)
+	fmt.Println()
+	return nil
+}
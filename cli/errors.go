@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sftsrv/lynks/theme"
+)
+
+const (
+	successExitCode = 0
+	// lint found errors or a command failed while running
+	errorExitCode = 1
+	// lynks can't run due to a problem with the config
+	configErrorExitCode = 2
+	// lynks was run with unknown commands, flags or invalid arguments
+	usageErrorExitCode = 2
+)
+
+// ConfigError prints each of the problems with the config to stderr and
+// returns the exit code
+func ConfigError(err error) int {
+	fmt.Fprintln(os.Stderr, theme.Alert.Render("Invalid config"))
+
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, theme.Warn.PaddingLeft(2).Render(err.Error()))
+	}
+
+	fmt.Fprintln(os.Stderr, theme.Faded.Render("Fix the problems above and try again"))
+	return configErrorExitCode
+}
+
+// UsageError prints a problem with the arguments lynks was run with to stderr
+// and returns the exit code. Output that is piped or sourced, such as the
+// completion scripts, shouldn't have the error in it
+func UsageError(err error) int {
+	fmt.Fprintln(os.Stderr, theme.Alert.Render("Invalid usage")+" "+err.Error())
+	return usageErrorExitCode
+}
+
+// RunError prints an error that stopped a command from completing to stderr
+// and returns the exit code
+func RunError(heading string, err error) int {
+	fmt.Fprintln(os.Stderr, theme.Alert.Render(heading)+" "+err.Error())
+	return errorExitCode
+}
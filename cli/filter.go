@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+)
+
+// Limits the files that are linted to the files, directories or globs given
+// on the command line. Globs use the same syntax as ignore patterns and are
+// relative to the current directory
+type fileFilter struct {
+	cwd string
+	// absolute paths of files and directories, and how they were given
+	paths []string
+	names []string
+	globs []config.Glob
+}
+
+func newFileFilter(patterns []string) (*fileFilter, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	f := &fileFilter{cwd: cwd}
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[") {
+			f.paths = append(f.paths, f.abs(pattern))
+			f.names = append(f.names, pattern)
+			continue
+		}
+
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q", pattern)
+		}
+
+		if filepath.IsAbs(pattern) {
+			rel, err := filepath.Rel(cwd, pattern)
+			if err != nil {
+				return nil, err
+			}
+
+			pattern = filepath.ToSlash(rel)
+		}
+
+		f.globs = append(f.globs, config.ParseGlob(pattern))
+	}
+
+	return f, nil
+}
+
+func (f *fileFilter) abs(p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+
+	return filepath.Join(f.cwd, p)
+}
+
+func within(abs string, allowed string) bool {
+	return abs == allowed || strings.HasPrefix(abs, allowed+string(filepath.Separator))
+}
+
+// A file or directory that doesn't match any of the paths is most likely a
+// typo, which would otherwise lint nothing and pass. Globs may match nothing
+func (f *fileFilter) check(paths []files.RelativePath) error {
+	if f == nil {
+		return nil
+	}
+
+	for i, allowed := range f.paths {
+		found := false
+		for _, p := range paths {
+			if within(f.abs(string(p)), allowed) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("%s doesn't match any markdown file that lynks checks", f.names[i])
+		}
+	}
+
+	return nil
+}
+
+func (f *fileFilter) matches(p files.RelativePath) bool {
+	if f == nil {
+		return true
+	}
+
+	abs := f.abs(string(p))
+	for _, allowed := range f.paths {
+		if within(abs, allowed) {
+			return true
+		}
+	}
+
+	rel, err := filepath.Rel(f.cwd, abs)
+	if err != nil {
+		return false
+	}
+
+	for _, glob := range f.globs {
+		if glob.Match(rel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *fileFilter) apply(paths []files.RelativePath) []files.RelativePath {
+	filtered := []files.RelativePath{}
+	for _, p := range paths {
+		if f.matches(p) {
+			filtered = append(filtered, p)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func TestLintFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"docs/a.md":        "See [b](docs/guides/b.md)",
+		"docs/guides/b.md": "See [a](../a.md)",
+		"docs/broken.md":   "See [missing](./missing.md)",
+	})
+
+	c, err := config.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := files.GetMarkdownFiles(c)
+
+	type Case struct {
+		files    []string
+		expected int
+	}
+
+	cases := []Case{
+		{[]string{}, errorExitCode},
+		{[]string{"docs/a.md"}, successExitCode},
+		{[]string{"./docs/guides"}, successExitCode},
+		{[]string{"docs/guides/*.md", "docs/a.md"}, successExitCode},
+		{[]string{"docs/**/*.md"}, errorExitCode},
+		{[]string{"*.md"}, errorExitCode},
+		{[]string{"docs/broken.md"}, errorExitCode},
+		{[]string{"notes.txt"}, usageErrorExitCode},
+		{[]string{"docs/typo.md"}, usageErrorExitCode},
+		{[]string{"docs/a.md", "missing"}, usageErrorExitCode},
+		{[]string{"missing/*.md"}, successExitCode},
+		{[]string{"[docs"}, usageErrorExitCode},
+	}
+
+	for _, c2 := range cases {
+		result := Lint(c, paths, LintOptions{Files: c2.files})
+		if result != c2.expected {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c2.files, result, c2.expected)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+	"github.com/sftsrv/lynks/theme"
+)
+
+type FixOptions struct {
+	// Only fix links in these files, directories or globs
+	Files []string
+	// Print the fixes without changing any files
+	DryRun bool
+}
+
+// A link that was changed, or that could not be fixed if fixed is empty
+type fix struct {
+	from  string
+	fixed string
+}
+
+// Fix updates the links that can be fixed without asking, i.e. remote links
+// to files in this repository and unresolved links to a file name that only
+// one markdown file has. Exits with 1 if any unresolved links are left
+func Fix(c config.Config, paths []files.RelativePath, options FixOptions) int {
+	filter, err := newFileFilter(options.Files)
+	if err != nil {
+		return UsageError(err)
+	}
+
+	if err := filter.check(paths); err != nil {
+		return UsageError(err)
+	}
+
+	index := files.NewIndex(c, paths)
+
+	byName := map[string][]files.RelativePath{}
+	for _, p := range index.Paths() {
+		name := filepath.Base(string(p))
+		byName[name] = append(byName[name], p)
+	}
+
+	fixedCount := 0
+	unfixedCount := 0
+
+	for _, path := range filter.apply(index.Paths()) {
+		// the index may not have the contents of the file if it was cached
+		file, links, err := files.ReadFile(c, path)
+		if err != nil {
+			return RunError("Could not read file", err)
+		}
+
+		fixes := []fix{}
+		original := file.Contents
+
+		for _, link := range links {
+			target, ok := fixTarget(link, byName)
+			if !ok {
+				if link.IsUnresolved() {
+					fixes = append(fixes, fix{from: link.Url})
+					unfixedCount++
+				}
+
+				continue
+			}
+
+			before := file.Contents
+			file = files.FixLink(c, file, link, target)
+
+			switch {
+			case file.Contents != before:
+				fixes = append(fixes, fix{from: link.Url, fixed: string(target)})
+				fixedCount++
+
+			// the link couldn't be rewritten so it's still broken
+			case link.IsUnresolved():
+				fixes = append(fixes, fix{from: link.Url})
+				unfixedCount++
+			}
+		}
+
+		if len(fixes) == 0 {
+			continue
+		}
+
+		fmt.Println(theme.Heading.Render(string(path)))
+		for _, f := range fixes {
+			if f.fixed == "" {
+				fmt.Println(theme.Warn.PaddingLeft(2).Render(f.from + " can't be fixed, no single file matches"))
+			} else {
+				fmt.Println(theme.Primary.PaddingLeft(2).Render(f.from + " -> " + f.fixed))
+			}
+		}
+
+		if options.DryRun || file.Contents == original {
+			continue
+		}
+
+		if err := os.WriteFile(string(file.Path), []byte(file.Contents), 0o644); err != nil {
+			return RunError("Could not update file", err)
+		}
+	}
+
+	verb := "Fixed"
+	if options.DryRun {
+		verb = "Would fix"
+	}
+
+	fmt.Println(theme.Faded.Render(fmt.Sprintf("%s %d links, %d unresolved links left", verb, fixedCount, unfixedCount)))
+
+	if unfixedCount > 0 {
+		return errorExitCode
+	}
+
+	return successExitCode
+}
+
+// The file a link should point to, if it's clear which one that is
+func fixTarget(link files.Link, byName map[string][]files.RelativePath) (files.RelativePath, bool) {
+	if link.ShouldBeLocal() {
+		_, err := os.Stat(string(link.Resolved))
+		return link.Resolved, err == nil
+	}
+
+	if !link.IsUnresolved() {
+		return "", false
+	}
+
+	name := link.FileName()
+	if !strings.HasSuffix(name, ".md") {
+		name += ".md"
+	}
+
+	candidates := byName[name]
+	if len(candidates) != 1 {
+		return "", false
+	}
+
+	return candidates[0], true
+}
+
+// Finds the path used by the index for a path given on the command line
+func indexedPath(index *files.Index, p string) (files.RelativePath, bool) {
+	candidates := []string{filepath.Clean(p)}
+	if abs, err := filepath.Abs(p); err == nil {
+		candidates = append(candidates, abs)
+
+		if cwd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(cwd, abs); err == nil {
+				candidates = append(candidates, rel)
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		if _, _, ok := index.Get(files.RelativePath(candidate)); ok {
+			return files.RelativePath(candidate), true
+		}
+	}
+
+	return files.RelativePath(filepath.Clean(p)), false
+}
+
+// Move renames a markdown file and updates the links to it as well as the
+// links in it, which are relative to where it was
+func Move(c config.Config, paths []files.RelativePath, from string, to string, dryRun bool) int {
+	index := files.NewIndex(c, paths)
+
+	source, ok := indexedPath(index, from)
+	if !ok {
+		return UsageError(fmt.Errorf("%s is not a markdown file that lynks checks", from))
+	}
+
+	// a destination ending in a separator is a directory even if it doesn't exist yet
+	dest := filepath.Clean(to)
+	if stat, err := os.Stat(dest); strings.HasSuffix(to, "/") || (err == nil && stat.IsDir()) {
+		dest = filepath.Join(dest, filepath.Base(string(source)))
+	}
+
+	if !strings.HasSuffix(dest, ".md") {
+		return UsageError(fmt.Errorf("%s is not a markdown file", to))
+	}
+
+	if _, err := os.Stat(dest); !errors.Is(err, os.ErrNotExist) {
+		return UsageError(fmt.Errorf("%s already exists", dest))
+	}
+
+	target := files.RelativePath(dest)
+	isSource := func(p files.RelativePath) bool {
+		return filepath.Clean(string(p)) == filepath.Clean(string(source))
+	}
+
+	moved, links, err := files.ReadFile(c, source)
+	if err != nil {
+		return RunError("Could not read file", err)
+	}
+
+	moved.Path = target
+	for _, link := range links {
+		// links to only an anchor stay in the same file
+		if !link.IsResolved() || strings.HasPrefix(link.Url, "#") {
+			continue
+		}
+
+		if isSource(link.Resolved) {
+			moved = files.FixLink(c, moved, link, target)
+		} else {
+			moved = files.FixLink(c, moved, link, link.Resolved)
+		}
+	}
+
+	updated := []files.File{}
+	for _, p := range index.Backlinks(source) {
+		if isSource(p) {
+			continue
+		}
+
+		file, links, err := files.ReadFile(c, p)
+		if err != nil {
+			return RunError("Could not read file", err)
+		}
+
+		before := file.Contents
+		for _, link := range links {
+			if link.IsResolved() && isSource(link.Resolved) {
+				file = files.FixLink(c, file, link, target)
+			}
+		}
+
+		if file.Contents != before {
+			updated = append(updated, file)
+		}
+	}
+
+	verb := "Moved"
+	if dryRun {
+		verb = "Would move"
+	}
+
+	fmt.Println(theme.Heading.Render(verb) + theme.Primary.MarginLeft(1).Render(string(source)+" -> "+string(target)))
+
+	for _, file := range updated {
+		fmt.Println(theme.Faded.PaddingLeft(2).Render("links updated in " + string(file.Path)))
+	}
+
+	if dryRun {
+		return successExitCode
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return RunError("Could not move file", err)
+	}
+
+	if err := os.Rename(string(source), dest); err != nil {
+		return RunError("Could not move file", err)
+	}
+
+	for _, file := range append(updated, moved) {
+		if err := os.WriteFile(string(file.Path), []byte(file.Contents), 0o644); err != nil {
+			return RunError("Could not update file", err)
+		}
+	}
+
+	return successExitCode
+}
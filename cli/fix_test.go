@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(buf)
+}
+
+func TestFix(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"lynks.config.json": `{ "localUrls": { "https://github.com/org/repo/blob/main/": "./" } }`,
+		"a.md":              "[b](https://github.com/org/repo/blob/main/docs/b.md#usage) [c](old/c.md) [d](d.md)",
+		"docs/b.md":         "# Usage",
+		"docs/c.md":         "# C",
+		"docs/d.md":         "# D",
+		"guides/d.md":       "# D",
+	})
+
+	c, err := config.Load("lynks.config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := files.GetMarkdownFiles(c)
+
+	if code := Fix(c, paths, FixOptions{DryRun: true}); code != errorExitCode {
+		t.Errorf("expected the ambiguous link to be left unresolved, got exit code %d", code)
+	}
+
+	if contents := readFile(t, "a.md"); contents != "[b](https://github.com/org/repo/blob/main/docs/b.md#usage) [c](old/c.md) [d](d.md)" {
+		t.Errorf("expected a dry run not to change the file, got %s", contents)
+	}
+
+	Fix(c, paths, FixOptions{})
+
+	expected := "[b](./docs/b.md#usage) [c](./docs/c.md) [d](d.md)"
+	if contents := readFile(t, "a.md"); contents != expected {
+		t.Errorf("\ngiven %v\ngot %v\nexpected %v", "a.md", contents, expected)
+	}
+}
+
+func TestMove(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"a.md": "[b](b.md) [b usage](b.md#usage)",
+		"b.md": "[a](a.md) [self](b.md) [top](#usage)",
+		"c.md": "no links",
+	})
+
+	c, err := config.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code := Move(c, files.GetMarkdownFiles(c), "b.md", "docs/", false); code != successExitCode {
+		t.Fatalf("expected the move to succeed, got exit code %d", code)
+	}
+
+	if _, err := os.Stat("b.md"); err == nil {
+		t.Errorf("expected b.md to be moved")
+	}
+
+	type Case struct {
+		file     string
+		expected string
+	}
+
+	cases := []Case{
+		{"a.md", "[b](./docs/b.md) [b usage](./docs/b.md#usage)"},
+		{"docs/b.md", "[a](../a.md) [self](./b.md) [top](#usage)"},
+		{"c.md", "no links"},
+	}
+
+	for _, c := range cases {
+		result := readFile(t, c.file)
+		if result != c.expected {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.file, result, c.expected)
+		}
+	}
+}
+
+// The links written by fix and mv should be resolved by lint
+func unresolvedLinks(t *testing.T, c config.Config) []string {
+	t.Helper()
+
+	unresolved := []string{}
+	for _, path := range files.GetMarkdownFiles(c) {
+		_, links, err := files.ReadFile(c, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, link := range links {
+			if link.IsUnresolved() {
+				unresolved = append(unresolved, string(path)+": "+link.Url)
+			}
+		}
+	}
+
+	return unresolved
+}
+
+func TestFixAndMoveWriteResolvedLinks(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"docs/a.md":        "[b](../docs/b.md) [c2](c2.md) [root](../readme.md)",
+		"docs/b.md":        "[a](./a.md) [c2](./guide/c2.md#usage)",
+		"docs/guide/c2.md": "[b](../b.md)",
+		"readme.md":        "[a](docs/a.md)",
+	})
+
+	c, err := config.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code := Fix(c, files.GetMarkdownFiles(c), FixOptions{}); code != successExitCode {
+		t.Errorf("expected fix to succeed, got exit code %d", code)
+	}
+
+	if unresolved := unresolvedLinks(t, c); len(unresolved) != 0 {
+		t.Errorf("expected no unresolved links after fix, got %v", unresolved)
+	}
+
+	if code := Fix(c, files.GetMarkdownFiles(c), FixOptions{}); code != successExitCode {
+		t.Errorf("expected a second fix to succeed, got exit code %d", code)
+	}
+
+	if code := Move(c, files.GetMarkdownFiles(c), "docs/b.md", "docs/sub/b.md", false); code != successExitCode {
+		t.Fatalf("expected the move to succeed, got exit code %d", code)
+	}
+
+	if unresolved := unresolvedLinks(t, c); len(unresolved) != 0 {
+		t.Errorf("expected no unresolved links after mv, got %v", unresolved)
+	}
+}
+
+func TestFixCountsLinksThatCantBeRewritten(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"a.md":        "[see [c]](c2.md)",
+		"guide/c2.md": "# C",
+	})
+
+	c, err := config.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code := Fix(c, files.GetMarkdownFiles(c), FixOptions{}); code != errorExitCode {
+		t.Errorf("expected the link that wasn't rewritten to be left unresolved, got exit code %d", code)
+	}
+}
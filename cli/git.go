@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+	"github.com/sftsrv/lynks/theme"
+)
+
+// Runs git and returns its output, errors include what git printed
+func git(args ...string) (string, error) {
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+
+		return "", fmt.Errorf("git %s: %s", args[0], message)
+	}
+
+	return stdout.String(), nil
+}
+
+// Reads the staged contents of the given markdown files that have staged
+// changes. The contents come from the git index rather than the working tree
+// so that only the changes that will be committed are checked
+func readStaged(paths []files.RelativePath) (map[files.RelativePath]string, error) {
+	top, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	// git resolves symlinks in the top level directory, so the paths it's
+	// compared to need to be resolved as well
+	top, err = filepath.EvalSymlinks(strings.TrimSpace(top))
+	if err != nil {
+		return nil, err
+	}
+
+	// deleted files have nothing to check
+	names, err := git("-C", top, "diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	staged := map[string]bool{}
+	for _, name := range strings.Split(names, "\x00") {
+		if name != "" {
+			staged[filepath.Join(top, filepath.FromSlash(name))] = true
+		}
+	}
+
+	contents := map[files.RelativePath]string{}
+	for _, p := range paths {
+		abs, err := filepath.Abs(string(p))
+		if err == nil {
+			abs, err = filepath.EvalSymlinks(abs)
+		}
+
+		if err != nil || !staged[abs] {
+			continue
+		}
+
+		rel, err := filepath.Rel(top, abs)
+		if err != nil {
+			return nil, err
+		}
+
+		blob, err := git("-C", top, "show", ":"+filepath.ToSlash(rel))
+		if err != nil {
+			return nil, err
+		}
+
+		contents[p] = blob
+	}
+
+	return contents, nil
+}
+
+const hookMarker = "# installed by lynks hook install"
+
+const hookScript = `#!/bin/sh
+` + hookMarker + `
+# checks the links in staged markdown files before committing
+exec lynks lint --staged
+`
+
+// HookInstall writes a git pre-commit hook that lints the staged markdown
+// files. Existing hooks are only replaced if force is set
+func HookInstall(force bool) int {
+	dir, err := git("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return RunError("Could not install hook", err)
+	}
+
+	hook := filepath.Join(strings.TrimSpace(dir), "pre-commit")
+
+	existing, err := os.ReadFile(hook)
+	if err == nil && !strings.Contains(string(existing), hookMarker) && !force {
+		return RunError("Could not install hook", fmt.Errorf("%s already exists, use --force to replace it", hook))
+	}
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return RunError("Could not install hook", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(hook), 0o755); err != nil {
+		return RunError("Could not install hook", err)
+	}
+
+	if err := os.WriteFile(hook, []byte(hookScript), 0o755); err != nil {
+		return RunError("Could not install hook", err)
+	}
+
+	// WriteFile doesn't change the mode of a file that already exists
+	if err := os.Chmod(hook, 0o755); err != nil {
+		return RunError("Could not install hook", err)
+	}
+
+	fmt.Println(theme.Heading.Render("Hook installed") + theme.Primary.MarginLeft(1).Render(hook))
+	return successExitCode
+}
+
+// An index of the staged contents of the files
+func stagedIndex(c config.Config, paths []files.RelativePath) (*files.Index, error) {
+	contents, err := readStaged(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	return files.NewIndexOf(c, files.ParseFiles(c, contents)), nil
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func gitRepository(t *testing.T) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+
+	if _, err := git("init", "--quiet"); err != nil {
+		t.Skip(err)
+	}
+}
+
+func TestLintStaged(t *testing.T) {
+	gitRepository(t)
+
+	testutil.WriteFiles(t, map[string]string{
+		"docs/a.md":     "See [missing](docs/missing.md)",
+		"docs/b.md":     "See [a](docs/a.md)",
+		"docs/other.md": "See [missing](docs/missing.md)",
+	})
+
+	if _, err := git("add", "docs/a.md", "docs/b.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the fix is only in the working tree so the staged file is still broken
+	testutil.WriteFiles(t, map[string]string{"docs/a.md": "See [b](docs/b.md)"})
+
+	c, err := config.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := files.GetMarkdownFiles(c)
+
+	contents, err := readStaged(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(contents) != 2 || contents["docs/a.md"] != "See [missing](docs/missing.md)" {
+		t.Errorf("expected the staged contents of docs/a.md and docs/b.md, got %v", contents)
+	}
+
+	type Case struct {
+		options  LintOptions
+		expected int
+	}
+
+	cases := []Case{
+		{LintOptions{Staged: true}, errorExitCode},
+		{LintOptions{Staged: true, Files: []string{"docs/b.md"}}, successExitCode},
+		{LintOptions{Files: []string{"docs/a.md", "docs/b.md"}}, successExitCode},
+		{LintOptions{Staged: true, Watch: true}, usageErrorExitCode},
+	}
+
+	for _, c2 := range cases {
+		result := Lint(c, paths, c2.options)
+		if result != c2.expected {
+			t.Errorf("\ngiven %+v\ngot %v\nexpected %v", c2.options, result, c2.expected)
+		}
+	}
+}
+
+func TestLintStagedFromSymlink(t *testing.T) {
+	dir := t.TempDir()
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Skip(err)
+	}
+
+	// the working directory keeps the symlink while git resolves it
+	t.Chdir(link)
+	if _, err := git("init", "--quiet"); err != nil {
+		t.Skip(err)
+	}
+
+	testutil.WriteFiles(t, map[string]string{"docs/a.md": "See [missing](docs/missing.md)"})
+	if _, err := git("add", "docs/a.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := config.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := readStaged(files.GetMarkdownFiles(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(contents) != 1 || contents["docs/a.md"] != "See [missing](docs/missing.md)" {
+		t.Errorf("expected the staged contents of docs/a.md, got %v", contents)
+	}
+}
+
+func TestHookInstall(t *testing.T) {
+	gitRepository(t)
+
+	hook := ".git/hooks/pre-commit"
+
+	if result := HookInstall(false); result != successExitCode {
+		t.Fatalf("expected the hook to be installed, got %v", result)
+	}
+
+	stat, err := os.Stat(hook)
+	if err != nil || stat.Mode().Perm()&0o111 == 0 {
+		t.Fatalf("expected an executable hook, got %v %v", stat, err)
+	}
+
+	// installing again replaces the hook since lynks installed it
+	if result := HookInstall(false); result != successExitCode {
+		t.Errorf("expected the hook to be reinstalled, got %v", result)
+	}
+
+	os.WriteFile(hook, []byte("#!/bin/sh\nmake check\n"), 0o755)
+
+	if result := HookInstall(false); result != errorExitCode {
+		t.Errorf("expected an existing hook not to be replaced, got %v", result)
+	}
+
+	if result := HookInstall(true); result != successExitCode {
+		t.Errorf("expected an existing hook to be replaced with --force, got %v", result)
+	}
+
+	contents, _ := os.ReadFile(hook)
+	if !strings.Contains(string(contents), "lynks lint --staged") {
+		t.Errorf("expected the hook to run lynks, got %s", contents)
+	}
+}
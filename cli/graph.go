@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+)
+
+var graphFormats = []string{"text", "dot", "json"}
+
+func checkGraphFormat(format string) error {
+	if !slices.Contains(graphFormats, format) {
+		return fmt.Errorf("unsupported format %q, expected one of %s", format, strings.Join(graphFormats, ", "))
+	}
+
+	return nil
+}
+
+// The local files that each file links to, files without links are included
+// with no targets
+func linkGraph(index *files.Index) map[files.RelativePath][]files.RelativePath {
+	graph := map[files.RelativePath][]files.RelativePath{}
+
+	for _, path := range index.Paths() {
+		_, links, _ := index.Get(path)
+
+		targets := []files.RelativePath{}
+		for _, link := range links {
+			if strings.HasPrefix(link.Url, "#") {
+				continue
+			}
+
+			if link.IsResolved() || link.ShouldBeLocal() {
+				targets = append(targets, files.RelativePath(filepath.Clean(string(link.Resolved))))
+			}
+		}
+
+		slices.Sort(targets)
+		graph[path] = slices.Compact(targets)
+	}
+
+	return graph
+}
+
+func renderGraph(graph map[files.RelativePath][]files.RelativePath, format string) (string, error) {
+	paths := slices.Sorted(maps.Keys(graph))
+	out := strings.Builder{}
+
+	switch format {
+	case "text":
+		for _, path := range paths {
+			for _, target := range graph[path] {
+				fmt.Fprintf(&out, "%s -> %s\n", path, target)
+			}
+		}
+
+	case "dot":
+		out.WriteString("digraph lynks {\n")
+		for _, path := range paths {
+			fmt.Fprintf(&out, "  %q;\n", path)
+			for _, target := range graph[path] {
+				fmt.Fprintf(&out, "  %q -> %q;\n", path, target)
+			}
+		}
+		out.WriteString("}\n")
+
+	case "json":
+		data, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return "", err
+		}
+
+		out.Write(data)
+		out.WriteString("\n")
+
+	default:
+		return "", checkGraphFormat(format)
+	}
+
+	return out.String(), nil
+}
+
+// Graph prints the links between the markdown files as text, a Graphviz dot
+// graph or JSON
+func Graph(c config.Config, paths []files.RelativePath, format string) int {
+	if err := checkGraphFormat(format); err != nil {
+		return UsageError(err)
+	}
+
+	rendered, err := renderGraph(linkGraph(files.NewIndex(c, paths)), format)
+	if err != nil {
+		return RunError("Could not render graph", err)
+	}
+
+	fmt.Print(rendered)
+	return successExitCode
+}
+
+// Backlinks prints the files that link to the given file, one per line. The
+// file doesn't need to exist so that links to a removed file can be found
+func Backlinks(c config.Config, paths []files.RelativePath, file string) int {
+	index := files.NewIndex(c, paths)
+	path, _ := indexedPath(index, file)
+
+	for _, backlink := range index.Backlinks(path) {
+		fmt.Println(backlink)
+	}
+
+	return successExitCode
+}
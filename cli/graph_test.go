@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func TestRenderGraph(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"a.md": "[b](b.md) [again](b.md) [c](c.md) [missing](missing.md) [remote](https://example.com)",
+		"b.md": "# B\n[a](a.md#intro) [top](#b)",
+		"c.md": "no links",
+	})
+
+	c, err := config.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graph := linkGraph(files.NewIndex(c, files.GetMarkdownFiles(c)))
+
+	type Case struct {
+		format   string
+		expected string
+	}
+
+	cases := []Case{
+		{"text", "a.md -> b.md\na.md -> c.md\nb.md -> a.md\n"},
+		{"dot", "digraph lynks {\n  \"a.md\";\n  \"a.md\" -> \"b.md\";\n  \"a.md\" -> \"c.md\";\n  \"b.md\";\n  \"b.md\" -> \"a.md\";\n  \"c.md\";\n}\n"},
+		{"json", "{\n  \"a.md\": [\n    \"b.md\",\n    \"c.md\"\n  ],\n  \"b.md\": [\n    \"a.md\"\n  ],\n  \"c.md\": []\n}\n"},
+	}
+
+	for _, c := range cases {
+		result, err := renderGraph(graph, c.format)
+		if err != nil || result != c.expected {
+			t.Errorf("\ngiven %v\ngot %v %v\nexpected %v", c.format, result, err, c.expected)
+		}
+	}
+}
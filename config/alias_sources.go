@@ -0,0 +1,261 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Reads the aliases defined in another tool's config. The aliases are keyed
+// by their prefix and map to directories relative to the working directory
+func readAliasSource(file string) (aliases, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(file)
+	switch {
+	case strings.HasSuffix(name, ".json"):
+		return tsconfigAliases(file, data)
+
+	case strings.HasPrefix(name, "docusaurus.config."):
+		found, err := jsAliases(file, data)
+
+		// docusaurus always aliases @site to the directory of its config
+		if _, ok := found["@site"]; !ok && err == nil {
+			found["@site"] = filepath.Dir(file)
+		}
+
+		return found, err
+
+	case strings.HasPrefix(name, "vite.config."):
+		return jsAliases(file, data)
+	}
+
+	return nil, fmt.Errorf("unsupported alias source %q, expected a tsconfig.json, jsconfig.json, docusaurus.config or vite.config file", file)
+}
+
+type tsconfig struct {
+	CompilerOptions struct {
+		BaseUrl string              `json:"baseUrl"`
+		Paths   map[string][]string `json:"paths"`
+	} `json:"compilerOptions"`
+}
+
+// Aliases from `compilerOptions.paths`, e.g. `"@components/*": ["./src/components/*"]`.
+// Only the first target of each path is used
+func tsconfigAliases(file string, data []byte) (aliases, error) {
+	stripped, err := stripJSONC(file, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var config tsconfig
+	if err := json.Unmarshal(stripped, &config); err != nil {
+		return nil, fmt.Errorf("could not parse %q: %v", file, err)
+	}
+
+	base := filepath.Join(filepath.Dir(file), config.CompilerOptions.BaseUrl)
+
+	found := aliases{}
+	for pattern, targets := range config.CompilerOptions.Paths {
+		alias := strings.TrimSuffix(pattern, "*")
+		if len(targets) == 0 || alias == "" || strings.Contains(alias, "*") {
+			continue
+		}
+
+		found[strings.TrimSuffix(alias, "/")] = filepath.Join(base, strings.TrimSuffix(targets[0], "*"))
+	}
+
+	return found, nil
+}
+
+var aliasKeyRe = regexp.MustCompile(`\balias\s*:\s*`)
+var stringRe = regexp.MustCompile("'([^']*)'|\"([^\"]*)\"|`([^`]*)`")
+
+// Finds the text between a bracket at the start of the text and the bracket
+// that closes it, skipping over strings
+func bracketed(text string) string {
+	depth := 0
+	quote := rune(0)
+
+	for i, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+
+		case r == '{' || r == '[' || r == '(':
+			depth++
+
+		case r == '}' || r == ']' || r == ')':
+			depth--
+			if depth == 0 {
+				return text[1:i]
+			}
+		}
+	}
+
+	return ""
+}
+
+// Splits the text on the separator when it is not nested in brackets or strings
+func splitTopLevel(text string, separator rune) []string {
+	parts := []string{}
+	depth := 0
+	quote := rune(0)
+	start := 0
+
+	for i, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+
+		case r == '{' || r == '[' || r == '(':
+			depth++
+
+		case r == '}' || r == ']' || r == ')':
+			depth--
+
+		case r == separator && depth == 0:
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, text[start:])
+}
+
+// The path that a JS expression such as `path.resolve(__dirname, 'src')` or
+// `fileURLToPath(new URL('./src', import.meta.url))` points to, found by
+// joining the strings in it
+func jsPath(dir string, expression string) (string, bool) {
+	parts := []string{dir}
+	for _, match := range stringRe.FindAllStringSubmatch(expression, -1) {
+		parts = append(parts, match[1]+match[2]+match[3])
+	}
+
+	return filepath.Join(parts...), len(parts) > 1
+}
+
+// The value of a key or a string, comments before it are skipped. Anything
+// else such as a regular expression is empty
+func unquote(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	text = strings.TrimSpace(lines[len(lines)-1])
+
+	if match := stringRe.FindStringSubmatch(text); match != nil && match[0] == text {
+		return match[1] + match[2] + match[3]
+	}
+
+	if strings.ContainsAny(text, "/[]{}()") {
+		return ""
+	}
+
+	return text
+}
+
+// Aliases from the `alias` options of a JS config, either an object such as
+// `alias: { '@': path.resolve(__dirname, './src') }` or an array such as
+// `alias: [{ find: '@', replacement: './src' }]`. The config isn't run so
+// only paths written as strings are found
+func jsAliases(file string, data []byte) (aliases, error) {
+	contents := string(data)
+	dir := filepath.Dir(file)
+	found := aliases{}
+
+	for _, match := range aliasKeyRe.FindAllStringIndex(contents, -1) {
+		value := contents[match[1]:]
+
+		if strings.HasPrefix(value, "{") {
+			for _, entry := range splitTopLevel(bracketed(value), ',') {
+				key, expression, ok := strings.Cut(entry, ":")
+				if !ok {
+					continue
+				}
+
+				if target, ok := jsPath(dir, expression); ok && unquote(key) != "" {
+					found[unquote(key)] = target
+				}
+			}
+		}
+
+		if strings.HasPrefix(value, "[") {
+			for _, entry := range splitTopLevel(bracketed(value), ',') {
+				fields := map[string]string{}
+				for _, field := range splitTopLevel(bracketed(strings.TrimSpace(entry)), ',') {
+					key, expression, _ := strings.Cut(field, ":")
+					fields[unquote(key)] = expression
+				}
+
+				find := unquote(fields["find"])
+				if target, ok := jsPath(dir, fields["replacement"]); ok && find != "" {
+					found[find] = target
+				}
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// Adds the aliases from the alias sources, aliases set in the config itself
+// take precedence and later sources take precedence over earlier ones. Only
+// aliases that point to directories are used
+func (s source) importAliases(config Config) (Config, []error) {
+	if len(config.AliasSources) == 0 {
+		return config, nil
+	}
+
+	errs := []error{}
+	imported := aliases{}
+	sources := map[string]string{}
+
+	for i, p := range config.AliasSources {
+		file := filepath.Join(config.Dir, p)
+
+		found, err := readAliasSource(file)
+		if err != nil {
+			errs = append(errs, s.errorf(fmt.Sprintf("aliasSources[%d]", i), "%v", err))
+			continue
+		}
+
+		for _, alias := range sortedKeys(found) {
+			rel, err := filepath.Rel(config.Root, found[alias])
+			if err != nil || !isDir(found[alias]) {
+				continue
+			}
+
+			imported[alias] = "./" + filepath.ToSlash(rel)
+			sources[alias] = file
+		}
+	}
+
+	merged := aliases{}
+	for alias, target := range config.Aliases {
+		merged[alias] = target
+	}
+
+	for alias, target := range imported {
+		if _, ok := merged[alias]; !ok {
+			merged[alias] = target
+			config.Sources[joinField("aliases", alias)] = sources[alias]
+		}
+	}
+
+	config.Aliases = merged
+	return config, errs
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func TestAliasSources(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"tsconfig.json": `{
+  // comments are allowed
+  "compilerOptions": {
+    "baseUrl": "./src",
+    "paths": {
+      "@components/*": ["./components/*"],
+      "@utils": ["./utils.ts"],
+      "@missing/*": ["./missing/*"],
+    }
+  }
+}`,
+		"docusaurus.config.js": `module.exports = {
+  plugins: [
+    () => ({
+      name: 'aliases',
+      configureWebpack: () => ({
+        resolve: {
+          alias: {
+            // shared snippets
+            '@snippets': path.resolve(__dirname, 'docs', 'snippets'),
+            "@api": path.resolve(__dirname, "./src/api"),
+          },
+        },
+      }),
+    }),
+  ],
+}`,
+		"website/vite.config.ts": `export default defineConfig({
+  resolve: {
+    alias: [
+      { find: '@guides', replacement: fileURLToPath(new URL('../docs/guides', import.meta.url)) },
+      { find: /^~(.+)/, replacement: '$1' },
+    ],
+  },
+})`,
+		"lynks.config.json": `{
+  "root": "./docs",
+  "aliasSources": ["tsconfig.json", "docusaurus.config.js", "website/vite.config.ts"],
+  "aliases": { "@api": "./api" }
+}`,
+		"src/components/.keep": "",
+		"src/utils.ts":         "",
+		"src/api/.keep":        "",
+		"docs/api/.keep":       "",
+		"docs/snippets/.keep":  "",
+		"docs/guides/.keep":    "",
+	})
+
+	config, err := Load("lynks.config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"@components": "./../src/components",
+		"@snippets":   "./snippets",
+		"@site":       "./..",
+		"@guides":     "./guides",
+		"@api":        "./api",
+	}
+
+	if !maps.Equal(config.Aliases, expected) {
+		t.Errorf("\ngot %v\nexpected %v", config.Aliases, expected)
+	}
+
+	type Case struct {
+		field    string
+		expected string
+	}
+
+	cases := []Case{
+		{"aliases.@components", "tsconfig.json"},
+		{"aliases.@site", "docusaurus.config.js"},
+		{"aliases.@guides", "website/vite.config.ts"},
+		{"aliases.@api", "lynks.config.json:4:16"},
+	}
+
+	for _, c := range cases {
+		result := config.Sources[c.field]
+		if result != c.expected {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.field, result, c.expected)
+		}
+	}
+}
+
+func TestAliasSourcesErrors(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"webpack.config.js": "",
+		"lynks.config.json": "{\n  \"aliasSources\": [\"tsconfig.json\", \"webpack.config.js\"]\n}",
+	})
+
+	_, err := Load("lynks.config.json")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	result := configErrors(t, err)
+	expected := []Error{
+		{Line: 2, Column: 20, Field: "aliasSources[0]"},
+		{Line: 2, Column: 37, Field: "aliasSources[1]"},
+	}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("\ngot %v\nexpected %v\n%v", result, expected, err)
+	}
+}
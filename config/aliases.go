@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+)
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_'
+}
+
+// Cuts the alias from the start of a link. The alias must be followed by a
+// separator so that `@api` doesn't match `@api-v2/page`, unless the alias
+// itself ends in one, e.g. `@` or `~/`
+func cutAlias(link string, alias string) (string, bool) {
+	after, ok := strings.CutPrefix(link, alias)
+	if !ok || after == "" || alias == "" {
+		return after, ok && alias != ""
+	}
+
+	last := []rune(alias)[len([]rune(alias))-1]
+	next := []rune(after)[0]
+
+	if isWordChar(last) && isWordChar(next) {
+		return "", false
+	}
+
+	return after, true
+}
+
+// Cuts a directory from the start of a path, only matching whole directories
+func cutDir(p string, dir string) (string, bool) {
+	if p == dir {
+		return "", true
+	}
+
+	if dir == "." {
+		return "/" + p, !strings.HasPrefix(p, "../") && !strings.HasPrefix(p, "/")
+	}
+
+	after, ok := strings.CutPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+	return "/" + after, ok
+}
+
+// Aliases ordered from the longest to the shortest so that the most specific
+// alias is used
+func (c Config) sortedAliases() []string {
+	return longestFirst(c.Aliases)
+}
+
+func (c Config) aliasTarget(alias string) string {
+	if IsUrl(c.Aliases[alias]) {
+		return c.Aliases[alias]
+	}
+
+	return path.Join(c.Root, c.Aliases[alias])
+}
+
+// Checks that a local alias points at a directory and that a remote alias is
+// a valid template
+func checkAlias(root string, target string) error {
+	if IsUrl(target) {
+		_, err := parseTemplate(target)
+		return err
+	}
+
+	if !isDir(path.Join(root, target)) {
+		return fmt.Errorf("directory %q does not exist", path.Join(root, target))
+	}
+
+	return nil
+}
+
+// Finds the longest alias at the start of a link and the rest of the link
+func (c Config) matchAlias(link string) (string, string, bool) {
+	for _, alias := range c.sortedAliases() {
+		if after, ok := cutAlias(link, alias); ok {
+			return alias, after, true
+		}
+	}
+
+	return "", "", false
+}
+
+// AddAlias replaces the start of a path with the alias whose target is the
+// most specific match, i.e. the longest target that contains the path
+func (c Config) AddAlias(link string) string {
+	found := ""
+	foundAfter := ""
+
+	for _, alias := range sortedKeys(c.Aliases) {
+		if IsUrl(c.Aliases[alias]) {
+			continue
+		}
+
+		after, ok := cutDir(link, c.aliasTarget(alias))
+		if !ok {
+			continue
+		}
+
+		// a shorter remainder means a more specific target, for aliases with
+		// the same target the shortest alias is used
+		if found == "" || len(after) < len(foundAfter) || (len(after) == len(foundAfter) && len(alias) < len(found)) {
+			found = alias
+			foundAfter = after
+		}
+	}
+
+	if found == "" {
+		return link
+	}
+
+	if foundAfter == "" {
+		return found
+	}
+
+	return strings.TrimSuffix(found, "/") + foundAfter
+}
+
+// RemoveAlias replaces the alias at the start of a link with its target, the
+// longest matching alias is used. Links using remote aliases are left as they
+// are, see RemoteAlias
+func (c Config) RemoveAlias(link string) string {
+	alias, after, ok := c.matchAlias(link)
+	if !ok || IsUrl(c.Aliases[alias]) {
+		return link
+	}
+
+	return path.Join(c.aliasTarget(alias), after)
+}
+
+// AmbiguousAliases describes aliases that can't be told apart, either because
+// they point at the same directory so it is unclear which should be used when
+// writing links, or because one alias is the start of another but does not
+// resolve links to the same place
+func (c Config) AmbiguousAliases() []string {
+	problems := []string{}
+	aliases := sortedKeys(c.Aliases)
+
+	for i, a := range aliases {
+		for _, b := range aliases[i+1:] {
+			if c.aliasTarget(a) == c.aliasTarget(b) {
+				problems = append(problems, fmt.Sprintf("%s and %s both point to %s", a, b, c.aliasTarget(a)))
+				continue
+			}
+
+			short, long := a, b
+			if len(short) > len(long) {
+				short, long = long, short
+			}
+
+			after, ok := cutAlias(long, short)
+			if !ok || IsUrl(c.Aliases[short]) || IsUrl(c.Aliases[long]) {
+				continue
+			}
+
+			if path.Join(c.aliasTarget(short), after) != c.aliasTarget(long) {
+				problems = append(problems, fmt.Sprintf("%s starts with %s but points to %s instead of %s", long, short, c.aliasTarget(long), path.Join(c.aliasTarget(short), after)))
+			}
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+var overlappingAliases = Config{
+	Root: "docs",
+	Aliases: map[string]string{
+		"@":        "./",
+		"@api":     "./api",
+		"@api-v2":  "./api/v2",
+		"@api/old": "./legacy/api",
+		"~/":       "./guides",
+		"@guides":  "./guides",
+	},
+}
+
+func TestRemoveAlias(t *testing.T) {
+	type Case struct {
+		link     string
+		expected string
+	}
+
+	cases := []Case{
+		{"@api/users.md", "docs/api/users.md"},
+		{"@api-v2/users.md", "docs/api/v2/users.md"},
+		{"@api/old/users.md", "docs/legacy/api/users.md"},
+		{"@api/older.md", "docs/api/older.md"},
+		{"@apis/users.md", "docs/apis/users.md"},
+		{"@guides/setup.md", "docs/guides/setup.md"},
+		{"~/setup.md", "docs/guides/setup.md"},
+		{"@api", "docs/api"},
+		{"./api/users.md", "./api/users.md"},
+	}
+
+	for _, c := range cases {
+		// map order is random so repeat to make sure the result is always the same
+		for range 20 {
+			result := overlappingAliases.RemoveAlias(c.link)
+			if result != c.expected {
+				t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.link, result, c.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestAddAlias(t *testing.T) {
+	type Case struct {
+		link     string
+		expected string
+	}
+
+	cases := []Case{
+		{"docs/api/users.md", "@api/users.md"},
+		{"docs/api/v2/users.md", "@api-v2/users.md"},
+		{"docs/api/v2", "@api-v2"},
+		{"docs/api-v2/users.md", "@/api-v2/users.md"},
+		{"docs/legacy/api/users.md", "@api/old/users.md"},
+		{"docs/guides/setup.md", "~/setup.md"},
+		{"docs/index.md", "@/index.md"},
+		{"other/index.md", "other/index.md"},
+	}
+
+	for _, c := range cases {
+		for range 20 {
+			result := overlappingAliases.AddAlias(c.link)
+			if result != c.expected {
+				t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.link, result, c.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestAmbiguousAliases(t *testing.T) {
+	type Case struct {
+		aliases  map[string]string
+		expected []string
+	}
+
+	cases := []Case{
+		{map[string]string{"@api": "./api", "@api-v2": "./api-v2"}, []string{}},
+		{map[string]string{"@api": "./api", "@api/v2": "./api/v2"}, []string{}},
+		{map[string]string{"@api": "./api", "@v1": "./api/"}, []string{"@api and @v1 both point to docs/api"}},
+		{map[string]string{"@api": "./api", "@api/v2": "./v2"}, []string{"@api/v2 starts with @api but points to docs/v2 instead of docs/api/v2"}},
+	}
+
+	for _, c := range cases {
+		config := Config{Root: "docs", Aliases: c.aliases}
+
+		result := config.AmbiguousAliases()
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.aliases, result, c.expected)
+		}
+	}
+}
@@ -1,10 +1,10 @@
 package config
 
 import (
-	"encoding/json"
-	"os"
 	"path"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
 type aliases = map[string]string
@@ -16,62 +16,91 @@ const (
 	RelativeResolutionStrategy ResolutionStrategy = "relative"
 )
 
+// Fields are described using a `description` tag which is used when generating
+// the JSON Schema for the config
 type Resolution struct {
-	Strategy      ResolutionStrategy `json:"strategy"`
-	KeepExtension bool               `json:"keepExtension"`
+	Strategy      ResolutionStrategy `json:"strategy" description:"How links are written when fixing them, relative to the file or relative to the root"`
+	KeepExtension bool               `json:"keepExtension" description:"Keep the .md extension when fixing links"`
+}
+
+type Remote struct {
+	Concurrency       int      `json:"concurrency" description:"Number of remote links that are checked at the same time"`
+	RequestsPerSecond float64  `json:"requestsPerSecond" description:"Maximum number of requests per second sent to a single host, 0 for no limit"`
+	Timeout           Duration `json:"timeout" description:"How long to wait for a response, e.g. 10s"`
+	Retries           int      `json:"retries" description:"Number of times a failed request is retried"`
+	CacheTTL          Duration `json:"cacheTTL" description:"How long results are kept in the cache, only used if the cache is enabled"`
 }
 
 type Config struct {
-	Root       string     `json:"root"`
-	Resolution Resolution `json:"resolution"`
-	Ignore     []string   `json:"ignore"`
-	Aliases    aliases    `json:"aliases"`
+	// Directory containing the config file, the root is relative to this
+	Dir string `json:"-"`
+	// The config file that was loaded, empty if the defaults are used
+	File string `json:"-"`
+	// Where each field was set, keyed by the field path. Fields that are not
+	// in here have their default value
+	Sources map[string]string `json:"-"`
+
+	Schema       string            `json:"$schema" description:"JSON Schema for the config, used by editors for completion"`
+	Extends      Extends           `json:"extends" description:"Configs that this config is based on, either a path or an array of paths. Relative paths start with ./ or ../, anything else is looked up in node_modules"`
+	Root         string            `json:"root" description:"Folder from which pages are resolved, relative to the config file"`
+	Resolution   Resolution        `json:"resolution" description:"How links are resolved and written"`
+	Ignore       []string          `json:"ignore" description:"Gitignore style patterns for paths within the root that are not checked"`
+	UseGitignore bool              `json:"useGitignore" description:"Also skip paths that are ignored by the .gitignore files of the repository"`
+	Aliases      aliases           `json:"aliases" description:"Link prefixes and the folders (relative to the root) or remote urls that they resolve to, urls can use {name} or {name:pattern} placeholders"`
+	AliasSources []string          `json:"aliasSources" description:"Files to read more aliases from, relative to the config file. Supports tsconfig.json and jsconfig.json paths, docusaurus.config and vite.config files"`
+	Cache        bool              `json:"cache" description:"Cache parsed files in .lynks/cache to speed up repeated runs"`
+	Remote       Remote            `json:"remote" description:"How remote links are checked when using lint --check-remote"`
+	LocalUrls    map[string]string `json:"localUrls" description:"Remote url prefixes that point at files in this repository and the folders (relative to the root) that they map to"`
+	Workspaces   []Workspace       `json:"workspaces" description:"Sets of docs with their own root, resolution, aliases and ignore patterns. Only the workspaces are checked if any are set"`
+	Rules        Rules             `json:"rules" description:"Severity of the problems reported by the linter, each can be off, warn or error"`
+	Overrides    []Override        `json:"overrides" description:"Resolution and rules for the files that match a pattern, applied in order"`
+
+	// name of the workspace selected using Select, empty for all workspaces
+	selected string
 }
 
-func (c Config) AddAlias(link string) string {
-	for alias, actual := range c.Aliases {
-		fullPrefix := path.Join(c.Root, actual)
+// ToLocal converts a remote url that points at a file in this repository into
+// the local path of the file. The longest matching prefix is used
+func (c Config) ToLocal(url string) (string, bool) {
+	for _, prefix := range longestFirst(c.LocalUrls) {
+		local := c.LocalUrls[prefix]
+		if after, ok := strings.CutPrefix(url, prefix); ok {
+			after, _, _ = strings.Cut(after, "#")
+			after, _, _ = strings.Cut(after, "?")
 
-		if after, ok := strings.CutPrefix(link, fullPrefix); ok {
-			return alias + after
+			return path.Join(c.Root, local, after), true
 		}
 	}
 
-	return link
+	return "", false
 }
 
-func (c Config) RemoveAlias(link string) string {
-	for alias, actual := range c.Aliases {
-		if after, ok := strings.CutPrefix(link, alias); ok {
-			return path.Join(c.Root, actual, after)
-		}
+// Rel converts a path relative to the working directory into a path relative
+// to the config directory
+func (c Config) Rel(p string) string {
+	rel, err := filepath.Rel(c.Dir, p)
+	if err != nil {
+		return p
 	}
 
-	return link
+	return rel
 }
 
 func defaultConfig() Config {
 	return Config{
+		Dir:  ".",
 		Root: "./",
 		Resolution: Resolution{
 			Strategy:      RelativeResolutionStrategy,
 			KeepExtension: true,
 		},
+		Remote: Remote{
+			Concurrency:       8,
+			RequestsPerSecond: 2,
+			Timeout:           Duration(10 * time.Second),
+			Retries:           2,
+			CacheTTL:          Duration(time.Hour),
+		},
+		Rules: defaultRules(),
 	}
 }
-
-func Load(path string) Config {
-	config := defaultConfig()
-
-	file, fileErr := os.ReadFile(path)
-	if fileErr != nil {
-		return config
-	}
-
-	jsonErr := json.Unmarshal(file, &config)
-	if jsonErr != nil {
-		return config
-	}
-
-	return config
-}
@@ -0,0 +1,35 @@
+package config
+
+import "testing"
+
+func TestToLocal(t *testing.T) {
+	config := Config{
+		Root: "./",
+		LocalUrls: map[string]string{
+			"https://github.com/org/repo/blob/main/":      "./",
+			"https://github.com/org/repo/blob/main/docs/": "./website/docs/",
+		},
+	}
+
+	type Case struct {
+		given    string
+		expected string
+	}
+
+	cases := []Case{
+		{"https://github.com/org/repo/blob/main/README.md", "README.md"},
+		{"https://github.com/org/repo/blob/main/docs/guide.md", "website/docs/guide.md"},
+		{"https://github.com/org/repo/blob/main/docs/guide.md#usage", "website/docs/guide.md"},
+		{"https://example.com/docs/guide.md", ""},
+	}
+
+	// map order is random so check a few times to catch order dependence
+	for range 20 {
+		for _, c := range cases {
+			result, _ := config.ToLocal(c.given)
+			if result != c.expected {
+				t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.given, result, c.expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+)
+
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Converts a byte offset into a line and column, both starting at 1
+func positionAt(data []byte, offset int64) Position {
+	offset = min(max(offset, 0), int64(len(data)))
+	before := data[:offset]
+
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+
+	return Position{Line: line, Column: column}
+}
+
+// Error describes a problem with the config. Line and Column are 0 if the
+// position in the file is not known (not all formats report columns) and
+// Field is empty for syntax errors
+type Error struct {
+	File    string
+	Line    int
+	Column  int
+	Field   string
+	Message string
+}
+
+func (e Error) Error() string {
+	location := e.File
+	if e.Line > 0 && e.Column > 0 {
+		location = fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+	} else if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", e.File, e.Line)
+	}
+
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", location, e.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", location, e.Field, e.Message)
+}
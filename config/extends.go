@@ -0,0 +1,250 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Extends lists the configs that a config is based on, either a single path
+// or an array of paths
+type Extends []string
+
+const extendsField = "extends"
+
+// Reads and removes `extends` from the source, it is not part of the config
+// itself since the extended configs are merged into the source
+func (s source) extends() ([]string, []string, error) {
+	value, ok := s.values[extendsField]
+	if !ok {
+		return nil, nil, nil
+	}
+
+	delete(s.values, extendsField)
+
+	if spec, ok := value.(string); ok {
+		return []string{spec}, []string{extendsField}, nil
+	}
+
+	array, ok := value.([]any)
+	if !ok {
+		return nil, nil, s.errorf(extendsField, "expected a path or an array of paths")
+	}
+
+	specs := []string{}
+	fields := []string{}
+	for i, item := range array {
+		field := fmt.Sprintf("%s[%d]", extendsField, i)
+
+		spec, ok := item.(string)
+		if !ok {
+			return nil, nil, s.errorf(field, "expected a path")
+		}
+
+		specs = append(specs, spec)
+		fields = append(fields, field)
+	}
+
+	return specs, fields, nil
+}
+
+// Resolves an extended config. Paths starting with `./`, `../` or `/` are
+// relative to the config that extends them, anything else is looked up in the
+// node_modules folders of the config's directory and its parents. If the path
+// is a directory the config in it is used
+func resolveExtends(file string, spec string) (string, error) {
+	dir := filepath.Dir(file)
+
+	p := ""
+	if filepath.IsAbs(spec) || strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../") {
+		p = filepath.Join(dir, spec)
+	} else {
+		for current := dir; ; current = filepath.Join(current, "..") {
+			candidate := filepath.Join(current, "node_modules", spec)
+			if exists(candidate) {
+				p = candidate
+				break
+			}
+
+			abs, err := filepath.Abs(current)
+			if err != nil || filepath.Dir(abs) == abs {
+				return "", fmt.Errorf("could not find %q in node_modules", spec)
+			}
+		}
+	}
+
+	if isDir(p) {
+		found, err := Find(p)
+		if err != nil {
+			return "", err
+		}
+
+		if found == "" {
+			return "", fmt.Errorf("no config found in %q", p)
+		}
+
+		return found, nil
+	}
+
+	if !exists(p) {
+		return "", fmt.Errorf("file %q does not exist", p)
+	}
+
+	return p, nil
+}
+
+// Reads a config file and merges any configs it extends into it. The chain
+// of files being loaded is used to detect configs that extend themselves
+func loadSource(file string, chain []string) (source, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return source{}, err
+	}
+
+	s, err := parse(file, data)
+	if err != nil {
+		return s, err
+	}
+
+	specs, fields, err := s.extends()
+	if err != nil || len(specs) == 0 {
+		return s, err
+	}
+
+	merged := source{file: file, values: map[string]any{}, positions: map[string]Position{}, files: map[string]string{}}
+	for i, spec := range specs {
+		p, err := resolveExtends(file, spec)
+		if err != nil {
+			return s, s.errorf(fields[i], "%v", err)
+		}
+
+		abs, _ := filepath.Abs(p)
+		if slices.Contains(chain, abs) {
+			return s, s.errorf(fields[i], "extending %q would create a loop", p)
+		}
+
+		base, err := loadSource(p, append(chain, abs))
+		if err != nil {
+			return s, err
+		}
+
+		merged = merged.merge(base)
+		merged.extended = append(merged.extended, p)
+	}
+
+	merged = merged.merge(s)
+
+	// extends is not part of the values but is still reported as a source
+	if position, ok := s.positions[extendsField]; ok {
+		merged.positions[extendsField] = position
+		merged.files[extendsField] = file
+	}
+
+	return merged, nil
+}
+
+// Every field path in a value, including objects and array items
+func fieldPaths(value any, field string) []string {
+	fields := []string{}
+	if field != "" {
+		fields = append(fields, field)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range sortedKeys(v) {
+			fields = append(fields, fieldPaths(v[key], joinField(field, key))...)
+		}
+
+	case []any:
+		for i, item := range v {
+			fields = append(fields, fieldPaths(item, fmt.Sprintf("%s[%d]", field, i))...)
+		}
+	}
+
+	return fields
+}
+
+// Merges other on top of the source. Objects are merged key by key, arrays
+// are concatenated and any other value in other replaces the one in the
+// source. Positions are moved along with the values so that errors are still
+// reported in the file and position that a value came from
+func (s source) merge(other source) source {
+	merged := source{
+		file:      other.file,
+		positions: map[string]Position{},
+		files:     map[string]string{},
+		extended:  slices.Concat(s.extended, other.extended),
+	}
+
+	for _, field := range fieldPaths(s.values, "") {
+		if position, ok := s.positions[field]; ok {
+			merged.positions[field] = position
+		}
+
+		merged.files[field] = s.fileOf(field)
+	}
+
+	merged.values = merged.mergeValue(s.values, other.values, other, "", "").(map[string]any)
+	return merged
+}
+
+// Copies the positions of a value from other, from is the field path in
+// other and to is the field path in the merged source
+func (s source) copyPositions(value any, other source, from string, to string) {
+	fromFields := fieldPaths(value, from)
+	toFields := fieldPaths(value, to)
+
+	for i, field := range fromFields {
+		if position, ok := other.positions[field]; ok {
+			s.positions[toFields[i]] = position
+		} else {
+			delete(s.positions, toFields[i])
+		}
+
+		s.files[toFields[i]] = other.fileOf(field)
+	}
+}
+
+func (s source) mergeValue(base any, value any, other source, from string, to string) any {
+	baseObject, baseIsObject := base.(map[string]any)
+	object, isObject := value.(map[string]any)
+
+	if baseIsObject && isObject {
+		merged := maps.Clone(baseObject)
+
+		for _, key := range sortedKeys(object) {
+			fromField := joinField(from, key)
+			toField := joinField(to, key)
+
+			if position, ok := other.positions[fromField]; ok {
+				s.positions[toField] = position
+			}
+
+			s.files[toField] = other.fileOf(fromField)
+			merged[key] = s.mergeValue(baseObject[key], object[key], other, fromField, toField)
+		}
+
+		return merged
+	}
+
+	baseArray, baseIsArray := base.([]any)
+	array, isArray := value.([]any)
+
+	if baseIsArray && isArray {
+		for i, item := range array {
+			s.copyPositions(item, other, fmt.Sprintf("%s[%d]", from, i), fmt.Sprintf("%s[%d]", to, len(baseArray)+i))
+		}
+
+		return slices.Concat(baseArray, array)
+	}
+
+	if to != "" {
+		s.copyPositions(value, other, from, to)
+	}
+
+	return value
+}
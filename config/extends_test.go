@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func TestExtends(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"node_modules/@org/lynks-config/lynks.config.json": `{
+  "aliases": { "@shared": "./shared" },
+  "ignore": ["node_modules"],
+  "rules": { "redirectedRemoteLinks": "off" }
+}`,
+		"base.lynks.json": `{
+  "extends": "@org/lynks-config",
+  "resolution": { "strategy": "root" },
+  "ignore": ["CHANGELOG.md"],
+  "rules": { "unresolvedLinks": "warn" }
+}`,
+		"lynks.config.json": `{
+  "extends": ["./base.lynks.json"],
+  "aliases": { "@api": "./api" },
+  "ignore": ["drafts/"],
+  "rules": { "unresolvedLinks": "error" }
+}`,
+		"shared/.keep": "",
+		"api/.keep":    "",
+	})
+
+	config, err := Load("lynks.config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedAliases := map[string]string{"@shared": "./shared", "@api": "./api"}
+	if !maps.Equal(config.Aliases, expectedAliases) {
+		t.Errorf("\ngot %v\nexpected %v", config.Aliases, expectedAliases)
+	}
+
+	expectedIgnore := []string{"node_modules", "CHANGELOG.md", "drafts/"}
+	if !slices.Equal(config.Ignore, expectedIgnore) {
+		t.Errorf("\ngot %v\nexpected %v", config.Ignore, expectedIgnore)
+	}
+
+	expectedRules := defaultRules()
+	expectedRules.RedirectedRemoteLinks = SeverityOff
+	if config.Rules != expectedRules || config.Resolution.Strategy != RootResolutionStrategy {
+		t.Errorf("\ngot %v %v\nexpected %v %v", config.Rules, config.Resolution.Strategy, expectedRules, RootResolutionStrategy)
+	}
+
+	expectedExtends := Extends{filepath.Join("node_modules", "@org", "lynks-config", "lynks.config.json"), "base.lynks.json"}
+	if !slices.Equal(config.Extends, expectedExtends) {
+		t.Errorf("\ngot %v\nexpected %v", config.Extends, expectedExtends)
+	}
+
+	type Case struct {
+		field    string
+		expected string
+	}
+
+	cases := []Case{
+		{"aliases.@shared", expectedExtends[0] + ":2:16"},
+		{"aliases.@api", "lynks.config.json:3:16"},
+		{"resolution.strategy", "base.lynks.json:3:19"},
+		{"rules.unresolvedLinks", "lynks.config.json:5:14"},
+		{"extends", "lynks.config.json:2:3"},
+		{"ignore", expectedExtends[0] + ":3:14, base.lynks.json:4:14, lynks.config.json:4:14"},
+	}
+
+	for _, c := range cases {
+		result := config.Sources[c.field]
+		if result != c.expected {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.field, result, c.expected)
+		}
+	}
+}
+
+func TestExtendsErrors(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"a.json":    `{ "extends": "./b.json" }`,
+		"b.json":    `{ "extends": "./a.json" }`,
+		"c.json":    `{ "extends": "missing-package" }`,
+		"base.json": "{\n  \"cache\": \"yes\"\n}",
+		"d.json":    "{\n  \"extends\": \"./base.json\",\n  \"ignore\": [1]\n}",
+	})
+
+	type Case struct {
+		file     string
+		expected []Error
+	}
+
+	cases := []Case{
+		{"a.json", []Error{{File: "b.json", Line: 1, Column: 3, Field: "extends"}}},
+		{"c.json", []Error{{File: "c.json", Line: 1, Column: 3, Field: "extends"}}},
+		{"d.json", []Error{
+			{File: "base.json", Line: 2, Column: 3, Field: "cache"},
+			{File: "d.json", Line: 3, Column: 14, Field: "ignore[0]"},
+		}},
+	}
+
+	for _, c := range cases {
+		_, err := Load(c.file)
+		if err == nil {
+			t.Errorf("%s: expected an error", c.file)
+			continue
+		}
+
+		errs := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = joined.Unwrap()
+		}
+
+		result := []Error{}
+		for _, err := range errs {
+			var configErr Error
+			errors.As(err, &configErr)
+			configErr.Message = ""
+			result = append(result, configErr)
+		}
+
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("%s:\ngot %v\nexpected %v\n%v", c.file, result, c.expected, err)
+		}
+	}
+}
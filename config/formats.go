@@ -0,0 +1,282 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config files that are looked for in order of preference
+var configFiles = []string{
+	"lynks.config.json",
+	"lynks.config.jsonc",
+	"lynks.config.yaml",
+	"lynks.config.yml",
+	"lynks.config.toml",
+}
+
+// The config can also be embedded in a package.json using this key
+const packageJSON = "package.json"
+const packageJSONKey = "lynks"
+
+func exists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+func hasPackageJSONConfig(p string) bool {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return false
+	}
+
+	// a package.json that isn't valid is not ours to report on
+	values := map[string]json.RawMessage{}
+	if json.Unmarshal(data, &values) != nil {
+		return false
+	}
+
+	_, ok := values[packageJSONKey]
+	return ok
+}
+
+// Find looks for a config file in the given directory and returns its path or
+// an empty string if there is none. Having more than one config is an error
+// since it would be unclear which one is used
+func Find(dir string) (string, error) {
+	found := []string{}
+
+	for _, name := range configFiles {
+		p := filepath.Join(dir, name)
+		if exists(p) {
+			found = append(found, p)
+		}
+	}
+
+	p := filepath.Join(dir, packageJSON)
+	if hasPackageJSONConfig(p) {
+		found = append(found, p)
+	}
+
+	if len(found) > 1 {
+		return "", fmt.Errorf("found multiple configs: %s, only one is allowed", strings.Join(found, ", "))
+	}
+
+	if len(found) == 0 {
+		return "", nil
+	}
+
+	return found[0], nil
+}
+
+// Parses a config file based on its name
+func parse(file string, data []byte) (source, error) {
+	switch {
+	case filepath.Base(file) == packageJSON:
+		return parsePackageJSON(file, data)
+
+	case strings.HasSuffix(file, ".yaml"), strings.HasSuffix(file, ".yml"):
+		return parseYAML(file, data)
+
+	case strings.HasSuffix(file, ".toml"):
+		return parseTOML(file, data)
+
+	default:
+		return parseJSON(file, data)
+	}
+}
+
+func parsePackageJSON(file string, data []byte) (source, error) {
+	pkg, err := parseJSON(file, data)
+	if err != nil {
+		return pkg, err
+	}
+
+	s := source{file: file, values: map[string]any{}, positions: map[string]Position{}}
+
+	values, ok := pkg.values[packageJSONKey].(map[string]any)
+	if !ok {
+		return s, pkg.errorf(packageJSONKey, "expected the config to be an object")
+	}
+
+	s.values = values
+	for field, position := range pkg.positions {
+		if after, ok := strings.CutPrefix(field, packageJSONKey+"."); ok {
+			s.positions[after] = position
+		}
+	}
+
+	return s, nil
+}
+
+var yamlLineRe = regexp.MustCompile(`^yaml: line (\d+): `)
+
+func parseYAML(file string, data []byte) (source, error) {
+	s := source{file: file, values: map[string]any{}, positions: map[string]Position{}}
+
+	document := yaml.Node{}
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		// yaml only reports the line that an error occurred on as part of the message
+		line := 0
+		message := strings.TrimPrefix(err.Error(), "yaml: ")
+		if match := yamlLineRe.FindStringSubmatch(err.Error()); match != nil {
+			line, _ = strconv.Atoi(match[1])
+			message = strings.TrimPrefix(err.Error(), match[0])
+		}
+
+		return s, Error{File: file, Line: line, Message: message}
+	}
+
+	// an empty file is an empty config
+	if len(document.Content) == 0 {
+		return s, nil
+	}
+
+	values, ok := walkYAML(document.Content[0], "", s.positions).(map[string]any)
+	if !ok {
+		return s, Error{File: file, Line: 1, Column: 1, Message: "expected the config to be a mapping"}
+	}
+
+	s.values = values
+	return s, nil
+}
+
+// Converts yaml nodes into the same values that encoding/json would produce
+// while recording the position of every key
+func walkYAML(node *yaml.Node, field string, positions map[string]Position) any {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return walkYAML(node.Alias, field, positions)
+
+	case yaml.MappingNode:
+		values := map[string]any{}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			child := joinField(field, key.Value)
+
+			positions[child] = Position{Line: key.Line, Column: key.Column}
+			values[key.Value] = walkYAML(node.Content[i+1], child, positions)
+		}
+
+		return values
+
+	case yaml.SequenceNode:
+		values := []any{}
+		for i, item := range node.Content {
+			child := fmt.Sprintf("%s[%d]", field, i)
+
+			positions[child] = Position{Line: item.Line, Column: item.Column}
+			values = append(values, walkYAML(item, child, positions))
+		}
+
+		return values
+
+	default:
+		var value any
+		node.Decode(&value)
+		return normalise(value)
+	}
+}
+
+func parseTOML(file string, data []byte) (source, error) {
+	s := source{file: file, values: map[string]any{}, positions: map[string]Position{}}
+
+	_, err := toml.Decode(string(data), &s.values)
+
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		return s, Error{File: file, Line: parseErr.Position.Line, Column: parseErr.Position.Col, Message: parseErr.Message}
+	}
+
+	if err != nil {
+		return s, Error{File: file, Message: err.Error()}
+	}
+
+	s.values = normalise(s.values).(map[string]any)
+	return s, nil
+}
+
+// Numbers are always float64 and arrays are always []any in decoded json so
+// values from other formats are converted to match
+func normalise(value any) any {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+
+	case int64:
+		return float64(v)
+
+	case uint64:
+		return float64(v)
+
+	case time.Time:
+		return v.Format(time.RFC3339)
+
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalise(item)
+		}
+
+		return v
+
+	case []map[string]any:
+		values := []any{}
+		for _, item := range v {
+			values = append(values, normalise(item))
+		}
+
+		return values
+
+	case []any:
+		for i, item := range v {
+			v[i] = normalise(item)
+		}
+
+		return v
+	}
+
+	return value
+}
+
+// Discover looks for a config file in dir and then in each of its parents,
+// stopping at the root of the git repository. Returns an empty string if there
+// is no config
+func Discover(dir string) (string, error) {
+	current := dir
+
+	for {
+		path, err := Find(current)
+		if err != nil || path != "" {
+			return path, err
+		}
+
+		if exists(filepath.Join(current, ".git")) {
+			return "", nil
+		}
+
+		parent := filepath.Join(current, "..")
+
+		absCurrent, err := filepath.Abs(current)
+		if err != nil {
+			return "", err
+		}
+
+		absParent, err := filepath.Abs(parent)
+		if err != nil || absParent == absCurrent {
+			return "", err
+		}
+
+		current = parent
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFormats(t *testing.T) {
+	type Case struct {
+		file     string
+		contents string
+	}
+
+	cases := []Case{
+		{"lynks.config.json", `{"root": "docs", "resolution": {"strategy": "root"}, "aliases": {"@api": "api"}, "remote": {"retries": 5}}`},
+		{"lynks.config.jsonc", "{\n  // comment\n  \"root\": \"docs\",\n  \"resolution\": {\"strategy\": \"root\"},\n  \"aliases\": {\"@api\": \"api\"},\n  \"remote\": {\"retries\": 5},\n}"},
+		{"lynks.config.yaml", "root: docs\nresolution:\n  strategy: root\naliases:\n  \"@api\": api\nremote:\n  retries: 5\n"},
+		{"lynks.config.yml", "root: docs\nresolution: {strategy: root}\naliases: {\"@api\": api}\nremote: {retries: 5}\n"},
+		{"lynks.config.toml", "root = \"docs\"\n\n[resolution]\nstrategy = \"root\"\n\n[aliases]\n\"@api\" = \"api\"\n\n[remote]\nretries = 5\n"},
+		{"package.json", `{"name": "docs", "lynks": {"root": "docs", "resolution": {"strategy": "root"}, "aliases": {"@api": "api"}, "remote": {"retries": 5}}}`},
+	}
+
+	for _, c := range cases {
+		dir := t.TempDir()
+		os.MkdirAll(filepath.Join(dir, "docs/api"), 0o755)
+		os.WriteFile(filepath.Join(dir, c.file), []byte(c.contents), 0o644)
+
+		path, err := Find(dir)
+		if err != nil || filepath.Base(path) != c.file {
+			t.Errorf("%s: expected config to be found, got %s %v", c.file, path, err)
+			continue
+		}
+
+		config, err := Load(path)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", c.file, err)
+			continue
+		}
+
+		if config.Root != filepath.Join(dir, "docs") || config.Resolution.Strategy != RootResolutionStrategy || config.Aliases["@api"] != "api" || config.Remote.Retries != 5 {
+			t.Errorf("%s: expected config to be loaded, got %v", c.file, config)
+		}
+	}
+}
+
+func TestLoadFormatErrors(t *testing.T) {
+	type Case struct {
+		file     string
+		contents string
+		expected Error
+	}
+
+	cases := []Case{
+		{"lynks.config.yaml", "root: ./\nresolution:\n  strategy: absolute\n", Error{Line: 3, Column: 3, Field: "resolution.strategy"}},
+		{"lynks.config.yaml", "root: ./\nroot2: [\n", Error{Line: 2}},
+		{"lynks.config.yaml", "root: ./\ncache: 5\n", Error{Line: 2, Column: 1, Field: "cache"}},
+		{"lynks.config.toml", "root = \"./\"\nrot = \"./\"\n", Error{Field: "rot"}},
+		{"lynks.config.toml", "root = \n", Error{Line: 1, Column: 8}},
+		{"package.json", "{\n  \"lynks\": {\n    \"cach\": true\n  }\n}", Error{Line: 3, Column: 5, Field: "cach"}},
+	}
+
+	for _, c := range cases {
+		path := filepath.Join(t.TempDir(), c.file)
+		os.WriteFile(path, []byte(c.contents), 0o644)
+
+		_, err := Load(path)
+		if err == nil {
+			t.Errorf("%s: expected an error", c.contents)
+			continue
+		}
+
+		result := configErrors(t, err)
+		if len(result) != 1 || result[0] != c.expected {
+			t.Errorf("\ngiven %s\ngot %v\nexpected %v\n%v", c.contents, result, c.expected, err)
+		}
+	}
+}
+
+func TestFindMultiple(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "lynks.config.json"), []byte("{}"), 0o644)
+	os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"lynks": {}}`), 0o644)
+
+	_, err := Find(dir)
+	if err == nil || !strings.Contains(err.Error(), "multiple") {
+		t.Errorf("expected an error for multiple configs, got %v", err)
+	}
+
+	// a package.json without config is not a config
+	os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "docs"}`), 0o644)
+
+	path, err := Find(dir)
+	if err != nil || filepath.Base(path) != "lynks.config.json" {
+		t.Errorf("expected the json config to be found, got %s %v", path, err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	os.MkdirAll("repo/.git", 0o755)
+	os.MkdirAll("repo/docs/guides", 0o755)
+	os.WriteFile("lynks.config.json", []byte("{}"), 0o644)
+
+	// configs outside of the git repository are not used
+	path, err := Discover("repo/docs/guides")
+	if err != nil || path != "" {
+		t.Errorf("expected no config outside of the repository, got %s %v", path, err)
+	}
+
+	os.WriteFile("repo/lynks.config.yaml", []byte("root: docs\n"), 0o644)
+	t.Chdir("repo/docs/guides")
+
+	path, err = Discover(".")
+	if err != nil || path != filepath.Join("..", "..", "lynks.config.yaml") {
+		t.Fatalf("expected config in the repository root, got %s %v", path, err)
+	}
+
+	config, err := Load(path)
+	if err != nil || config.Root != filepath.Join("..", "..", "docs") {
+		t.Errorf("expected root to be relative to the config, got %s %v", config.Root, err)
+	}
+}
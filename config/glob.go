@@ -0,0 +1,58 @@
+package config
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Glob is a gitignore style pattern split on `/`. A pattern without a `/` in
+// it matches at any depth and `**` matches any number of directories
+type Glob []string
+
+func ParseGlob(pattern string) Glob {
+	pattern = strings.TrimPrefix(pattern, "./")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	glob := Glob(strings.Split(pattern, "/"))
+	if !anchored {
+		glob = append(Glob{"**"}, glob...)
+	}
+
+	return glob
+}
+
+// Checks that each part of the pattern is valid
+func validGlob(pattern string) bool {
+	_, err := path.Match(pattern, "")
+	return err == nil
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := range len(segments) + 1 {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	ok, _ := path.Match(pattern[0], segments[0])
+	return ok && matchSegments(pattern[1:], segments[1:])
+}
+
+// Match checks a path relative to where the pattern is defined
+func (g Glob) Match(rel string) bool {
+	return matchSegments(g, strings.Split(filepath.ToSlash(rel), "/"))
+}
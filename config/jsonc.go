@@ -0,0 +1,84 @@
+package config
+
+// Removes comments and trailing commas from JSONC so that it can be parsed as
+// JSON. Everything that is removed is replaced with spaces (newlines are kept)
+// so that offsets into the result are the same as offsets into the original
+func stripJSONC(file string, data []byte) ([]byte, error) {
+	result := make([]byte, len(data))
+	copy(result, data)
+
+	blank := func(from int, to int) {
+		for i := from; i < to; i++ {
+			if result[i] != '\n' && result[i] != '\r' {
+				result[i] = ' '
+			}
+		}
+	}
+
+	// the position of a comma that may turn out to be trailing
+	comma := -1
+	// the last character that was not whitespace or part of a comment
+	last := byte(0)
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		switch {
+		case c == '"':
+			comma = -1
+			last = c
+
+			// skip to the end of the string, leaving escaped characters alone
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			start := i
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+
+			blank(start, i)
+
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			start := i
+			for i += 2; i < len(data) && !(data[i] == '*' && i+1 < len(data) && data[i+1] == '/'); i++ {
+			}
+
+			if i >= len(data) {
+				position := positionAt(data, int64(start))
+				return result, Error{File: file, Line: position.Line, Column: position.Column, Message: "comment is never closed, expected */"}
+			}
+
+			i++
+			blank(start, i+1)
+
+		case c == ',':
+			// only a comma after a value can be a trailing comma
+			if last != ',' && last != ':' && last != '{' && last != '[' {
+				comma = i
+			}
+
+			last = c
+
+		case c == '}' || c == ']':
+			if comma >= 0 {
+				blank(comma, comma+1)
+			}
+
+			comma = -1
+			last = c
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+
+		default:
+			comma = -1
+			last = c
+		}
+	}
+
+	return result, nil
+}
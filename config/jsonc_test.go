@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadJSONC(t *testing.T) {
+	path := writeConfig(t, `{
+  // root folder from which pages should be resolved
+  "root": "./docs",
+  /* if not provided will default to relative */
+  "resolution": {
+    "strategy": "root", // options are root | relative
+    "keepExtension": false,
+  },
+  "aliases": {
+    "@api": "./generated/api",
+  },
+  "localUrls": {
+    "https://github.com/org/repo/blob/main/docs/": "./", // not a comment: "//"
+  },
+  "ignore": ["a,", "b",],
+}`)
+
+	os.MkdirAll(filepath.Join(filepath.Dir(path), "docs/generated/api"), 0o755)
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if config.Root != filepath.Join(filepath.Dir(path), "docs") || config.Aliases["@api"] != "./generated/api" {
+		t.Errorf("expected config to be loaded, got %v", config)
+	}
+
+	if _, ok := config.LocalUrls["https://github.com/org/repo/blob/main/docs/"]; !ok {
+		t.Errorf("expected urls in strings to be kept, got %v", config.LocalUrls)
+	}
+
+	if len(config.Ignore) != 2 || config.Ignore[0] != "a," {
+		t.Errorf("expected commas in strings to be kept, got %v", config.Ignore)
+	}
+}
+
+func TestLoadJSONCErrors(t *testing.T) {
+	type Case struct {
+		name     string
+		contents string
+		expected Error
+	}
+
+	cases := []Case{
+		{
+			"unclosed comment",
+			"{\n  \"root\": \"./\"\n  /* unclosed\n}",
+			Error{Line: 3, Column: 3},
+		},
+		{
+			// errors after comments are still reported at their original position
+			"missing comma",
+			"{\n  // a comment\n  \"root\": \"./\" /* another */ \"cache\": true\n}",
+			Error{Line: 3, Column: 30},
+		},
+	}
+
+	for _, c := range cases {
+		_, err := Load(writeConfig(t, c.contents))
+		if err == nil {
+			t.Errorf("%s: expected an error", c.name)
+			continue
+		}
+
+		result := configErrors(t, err)
+		if len(result) != 1 || result[0] != c.expected {
+			t.Errorf("%s:\ngot %v\nexpected %v\n%v", c.name, result, c.expected, err)
+		}
+	}
+}
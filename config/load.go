@@ -0,0 +1,509 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// A source is a config file that has been parsed but not yet checked against
+// the shape of the Config
+type source struct {
+	file   string
+	values map[string]any
+	// Positions of each field in the file, keyed by the field path, e.g. `resolution.strategy`
+	positions map[string]Position
+	// Files that fields come from when configs are extended, keyed by the field
+	// path. Fields that are not in here come from the file of the source
+	files map[string]string
+	// Configs that were extended, in the order that they were applied
+	extended []string
+}
+
+func joinField(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+
+	return parent + "." + key
+}
+
+var indexRe = regexp.MustCompile(`\[\d+\]$`)
+
+func parentField(field string) string {
+	if indexRe.MatchString(field) {
+		return indexRe.ReplaceAllString(field, "")
+	}
+
+	if i := strings.LastIndex(field, "."); i >= 0 {
+		return field[:i]
+	}
+
+	return ""
+}
+
+// The file a field comes from
+func (s source) fileOf(field string) string {
+	for ; field != ""; field = parentField(field) {
+		if file, ok := s.files[field]; ok {
+			return file
+		}
+	}
+
+	return s.file
+}
+
+// File and position of a field, falling back to the closest parent in the same
+// file if the position of the field itself is not known
+func (s source) lookup(field string) (string, Position) {
+	file := s.fileOf(field)
+
+	for ; field != ""; field = parentField(field) {
+		if position, ok := s.positions[field]; ok && s.fileOf(field) == file {
+			return file, position
+		}
+	}
+
+	return file, Position{}
+}
+
+func (s source) errorf(field string, format string, args ...any) error {
+	file, position := s.lookup(field)
+
+	return Error{
+		File:    file,
+		Line:    position.Line,
+		Column:  position.Column,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// Parses JSON with comments and trailing commas, plain JSON is also valid
+func parseJSON(file string, data []byte) (source, error) {
+	s := source{file: file, values: map[string]any{}, positions: map[string]Position{}}
+
+	data, err := stripJSONC(file, data)
+	if err != nil {
+		return s, err
+	}
+
+	err = json.Unmarshal(data, &s.values)
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// the offset is just after the character that caused the error
+		position := positionAt(data, syntaxErr.Offset-1)
+		return s, Error{File: file, Line: position.Line, Column: position.Column, Message: syntaxErr.Error()}
+	}
+
+	if err != nil {
+		return s, Error{File: file, Line: 1, Column: 1, Message: "expected the config to be an object"}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	walkJSON(decoder, data, "", s.positions)
+
+	return s, nil
+}
+
+// Records the position of every key in the document. The document has
+// already been parsed successfully so errors can't occur here
+func walkJSON(decoder *json.Decoder, data []byte, field string, positions map[string]Position) {
+	token, err := decoder.Token()
+	if err != nil {
+		return
+	}
+
+	switch token {
+	case json.Delim('{'):
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return
+			}
+
+			key := token.(string)
+			child := joinField(field, key)
+
+			// the decoder is just after the closing quote of the key
+			end := decoder.InputOffset()
+			start := bytes.LastIndexByte(data[:end-1], '"')
+			positions[child] = positionAt(data, int64(start))
+
+			walkJSON(decoder, data, child, positions)
+		}
+
+		decoder.Token()
+
+	case json.Delim('['):
+		for i := 0; decoder.More(); i++ {
+			child := fmt.Sprintf("%s[%d]", field, i)
+
+			// the decoder is just after the previous token so the item starts
+			// after any whitespace and the separating comma
+			start := decoder.InputOffset()
+			for start < int64(len(data)) && strings.ContainsRune(" \t\r\n,", rune(data[start])) {
+				start++
+			}
+
+			positions[child] = positionAt(data, start)
+			walkJSON(decoder, data, child, positions)
+		}
+
+		decoder.Token()
+	}
+}
+
+// Maps the json name of each field in a struct to its type
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		if field.IsExported() && name != "" && name != "-" {
+			fields[name] = field.Type
+		}
+	}
+
+	return fields
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+	return keys
+}
+
+// Keys ordered from the longest to the shortest so that the most specific
+// prefix is used, ties are ordered by name so the order is always the same
+func longestFirst[V any](m map[string]V) []string {
+	keys := sortedKeys(m)
+
+	slices.SortStableFunc(keys, func(a string, b string) int {
+		return len(b) - len(a)
+	})
+
+	return keys
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// Checks that the value has the shape expected by the given type, reporting
+// unknown fields and values of the wrong type
+func (s source) check(value any, t reflect.Type, field string) []error {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		str, ok := value.(string)
+		if !ok {
+			return []error{s.errorf(field, "expected a string")}
+		}
+
+		target := reflect.New(t).Interface().(encoding.TextUnmarshaler)
+		err := target.UnmarshalText([]byte(str))
+		if err != nil {
+			return []error{s.errorf(field, "%v", err)}
+		}
+
+		return nil
+	}
+
+	errs := []error{}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.check(value, t.Elem(), field)
+
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return []error{s.errorf(field, "expected an object")}
+		}
+
+		fields := jsonFields(t)
+		for _, key := range sortedKeys(object) {
+			child := joinField(field, key)
+
+			fieldType, ok := fields[key]
+			if !ok {
+				errs = append(errs, s.errorf(child, "unknown field, expected one of %s", strings.Join(sortedKeys(fields), ", ")))
+				continue
+			}
+
+			errs = append(errs, s.check(object[key], fieldType, child)...)
+		}
+
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return []error{s.errorf(field, "expected an object")}
+		}
+
+		for _, key := range sortedKeys(object) {
+			errs = append(errs, s.check(object[key], t.Elem(), joinField(field, key))...)
+		}
+
+	case reflect.Slice:
+		array, ok := value.([]any)
+		if !ok {
+			return []error{s.errorf(field, "expected an array")}
+		}
+
+		for i, item := range array {
+			errs = append(errs, s.check(item, t.Elem(), fmt.Sprintf("%s[%d]", field, i))...)
+		}
+
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			return []error{s.errorf(field, "expected a string")}
+		}
+
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return []error{s.errorf(field, "expected true or false")}
+		}
+
+	case reflect.Int:
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return []error{s.errorf(field, "expected a whole number")}
+		}
+
+	case reflect.Float64:
+		if _, ok := value.(float64); !ok {
+			return []error{s.errorf(field, "expected a number")}
+		}
+	}
+
+	return errs
+}
+
+// Location of a field in the file in the same format as errors
+func (s source) location(field string) string {
+	file, position := s.lookup(field)
+	if position.Line == 0 {
+		return file
+	}
+
+	if position.Column == 0 {
+		return fmt.Sprintf("%s:%d", file, position.Line)
+	}
+
+	return fmt.Sprintf("%s:%d:%d", file, position.Line, position.Column)
+}
+
+// The location of every value that is set by the source, keyed by field path
+func (s source) sources() map[string]string {
+	sources := map[string]string{}
+
+	for _, field := range flatten(s.values, "") {
+		sources[field] = s.location(field)
+
+		// arrays from extended configs are concatenated so each item is
+		// reported if they come from different files
+		array, ok := valueAt(s.values, field).([]any)
+		if !ok {
+			continue
+		}
+
+		files := map[string]bool{}
+		locations := []string{}
+		for i := range array {
+			item := fmt.Sprintf("%s[%d]", field, i)
+			files[s.fileOf(item)] = true
+			locations = append(locations, s.location(item))
+		}
+
+		if len(files) > 1 {
+			sources[field] = strings.Join(locations, ", ")
+		}
+	}
+
+	return sources
+}
+
+// The value of a field in nested objects
+func valueAt(values map[string]any, field string) any {
+	var value any = values
+
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		value = object[key]
+	}
+
+	return value
+}
+
+// Field paths of all values that are not objects, empty objects are included
+func flatten(value any, field string) []string {
+	object, ok := value.(map[string]any)
+	if !ok || (len(object) == 0 && field != "") {
+		return []string{field}
+	}
+
+	fields := []string{}
+	for _, key := range sortedKeys(object) {
+		fields = append(fields, flatten(object[key], joinField(field, key))...)
+	}
+
+	return fields
+}
+
+// Joins errors in the order that they appear in the file
+func joinErrors(errs []error) error {
+	slices.SortStableFunc(errs, func(a error, b error) int {
+		var aErr, bErr Error
+		errors.As(a, &aErr)
+		errors.As(b, &bErr)
+
+		if aErr.Line != bErr.Line {
+			return aErr.Line - bErr.Line
+		}
+
+		return aErr.Column - bErr.Column
+	})
+
+	return errors.Join(errs...)
+}
+
+// Decodes the source on top of the given config and validates the result
+func (s source) decode(config Config) (Config, error) {
+	errs := s.check(s.values, reflect.TypeFor[Config](), "")
+	if len(errs) > 0 {
+		return config, joinErrors(errs)
+	}
+
+	data, err := json.Marshal(s.values)
+	if err != nil {
+		return config, err
+	}
+
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return config, Error{File: s.file, Message: err.Error()}
+	}
+
+	config.File = s.file
+	config.Sources = s.sources()
+
+	config.Extends = s.extended
+	if len(s.extended) > 0 {
+		config.Sources[extendsField] = s.location(extendsField)
+	}
+
+	// paths in the config are relative to the config file and not to wherever
+	// lynks is being run from
+	config.Dir = filepath.Dir(s.file)
+	if !filepath.IsAbs(config.Root) {
+		config.Root = filepath.Join(config.Dir, config.Root)
+	}
+
+	config, errs = s.importAliases(config)
+
+	config, workspaceErrs := s.expandWorkspaces(config)
+	errs = append(errs, workspaceErrs...)
+
+	config, overrideErrs := s.prepareOverrides(config)
+	errs = append(errs, overrideErrs...)
+	errs = append(errs, s.validate(config)...)
+	if len(errs) > 0 {
+		return config, joinErrors(errs)
+	}
+
+	return config, nil
+}
+
+func isDir(p string) bool {
+	stat, err := os.Stat(p)
+	return err == nil && stat.IsDir()
+}
+
+func (s source) validate(config Config) []error {
+	errs := []error{}
+
+	strategy := config.Resolution.Strategy
+	if strategy != RootResolutionStrategy && strategy != RelativeResolutionStrategy {
+		errs = append(errs, s.errorf("resolution.strategy", "invalid strategy %q, expected %q or %q", strategy, RootResolutionStrategy, RelativeResolutionStrategy))
+	}
+
+	if !isDir(config.Root) {
+		errs = append(errs, s.errorf("root", "directory %q does not exist", config.Root))
+	}
+
+	for _, alias := range sortedKeys(config.Aliases) {
+		if err := checkAlias(config.Root, config.Aliases[alias]); err != nil {
+			errs = append(errs, s.errorf(joinField("aliases", alias), "%v", err))
+		}
+	}
+
+	for i, pattern := range config.Ignore {
+		if !validGlob(strings.TrimPrefix(pattern, "!")) {
+			errs = append(errs, s.errorf(fmt.Sprintf("ignore[%d]", i), "invalid pattern %q", pattern))
+		}
+	}
+
+	for _, prefix := range sortedKeys(config.LocalUrls) {
+		if !IsUrl(prefix) {
+			errs = append(errs, s.errorf(joinField("localUrls", prefix), "expected a url starting with http:// or https://"))
+		}
+	}
+
+	if config.Remote.Concurrency < 1 {
+		errs = append(errs, s.errorf("remote.concurrency", "expected at least 1"))
+	}
+
+	if config.Remote.RequestsPerSecond < 0 {
+		errs = append(errs, s.errorf("remote.requestsPerSecond", "expected 0 (no limit) or more"))
+	}
+
+	if config.Remote.Retries < 0 {
+		errs = append(errs, s.errorf("remote.retries", "expected 0 or more"))
+	}
+
+	if config.Remote.Timeout <= 0 {
+		errs = append(errs, s.errorf("remote.timeout", "expected a duration greater than 0"))
+	}
+
+	for _, w := range config.Workspaces {
+		errs = append(errs, s.validateWorkspace(w)...)
+	}
+
+	return errs
+}
+
+// Load reads the config file on top of the defaults. If the file does not
+// exist the error wraps fs.ErrNotExist, any problems with the contents of the
+// file are reported as one or more Errors. An empty path loads the defaults
+func Load(path string) (Config, error) {
+	config := defaultConfig()
+	if path == "" {
+		return config, nil
+	}
+
+	abs, _ := filepath.Abs(path)
+
+	s, err := loadSource(path, []string{abs})
+	if err != nil {
+		return config, err
+	}
+
+	return s.decode(config)
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "lynks.config.json")
+	err := os.WriteFile(path, []byte(contents), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// Flattens joined errors into the Errors they contain
+func configErrors(t *testing.T, err error) []Error {
+	t.Helper()
+
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	result := []Error{}
+	for _, err := range errs {
+		var configErr Error
+		if !errors.As(err, &configErr) {
+			t.Fatalf("expected a config error, got %v", err)
+		}
+
+		// the file is different for every test so is not compared
+		configErr.File = ""
+		configErr.Message = ""
+		result = append(result, configErr)
+	}
+
+	return result
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "lynks.config.json"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
+
+func TestLoadValid(t *testing.T) {
+	path := writeConfig(t, `{
+  "root": "docs",
+  "resolution": { "strategy": "root" },
+  "aliases": { "@api": "api" },
+  "remote": { "timeout": "5s" }
+}`)
+
+	dir := filepath.Dir(path)
+	os.MkdirAll(filepath.Join(dir, "docs/api"), 0o755)
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// the root is relative to the config file
+	if config.Root != filepath.Join(dir, "docs") || config.Resolution.Strategy != RootResolutionStrategy {
+		t.Errorf("expected config to be loaded, got %v", config)
+	}
+
+	// fields that are not in the file keep their defaults
+	if !config.Resolution.KeepExtension || config.Remote.Concurrency != defaultConfig().Remote.Concurrency {
+		t.Errorf("expected defaults to be kept, got %v", config)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	type Case struct {
+		name     string
+		contents string
+		expected []Error
+	}
+
+	cases := []Case{
+		{
+			"syntax",
+			"{\n  \"root\": ,\n}",
+			[]Error{{Line: 2, Column: 11}},
+		},
+		{
+			"unknown fields",
+			"{\n  \"rot\": \"./\",\n  \"resolution\": { \"keepExt\": true }\n}",
+			[]Error{
+				{Line: 2, Column: 3, Field: "rot"},
+				{Line: 3, Column: 19, Field: "resolution.keepExt"},
+			},
+		},
+		{
+			"types",
+			"{\n  \"cache\": \"yes\",\n  \"ignore\": [1],\n  \"remote\": { \"timeout\": \"soon\" }\n}",
+			[]Error{
+				{Line: 2, Column: 3, Field: "cache"},
+				{Line: 3, Column: 14, Field: "ignore[0]"},
+				{Line: 4, Column: 15, Field: "remote.timeout"},
+			},
+		},
+		{
+			"validation",
+			"{\n  \"root\": \"./does-not-exist\",\n  \"resolution\": {\n    \"strategy\": \"absolute\"\n  }\n}",
+			[]Error{
+				{Line: 2, Column: 3, Field: "root"},
+				{Line: 4, Column: 5, Field: "resolution.strategy"},
+			},
+		},
+		{
+			"ignore patterns",
+			"{\n  \"ignore\": [\"*.md\", \"[docs\"]\n}",
+			[]Error{{Line: 2, Column: 22, Field: "ignore[1]"}},
+		},
+		{
+			"remote aliases",
+			"{\n  \"aliases\": {\n    \"@issues\": \"https://tracker.local/{id}\",\n    \"@jira\": \"https://jira.local/{key\"\n  }\n}",
+			[]Error{{Line: 4, Column: 5, Field: "aliases.@jira"}},
+		},
+	}
+
+	for _, c := range cases {
+		_, err := Load(writeConfig(t, c.contents))
+		if err == nil {
+			t.Errorf("%s: expected an error", c.name)
+			continue
+		}
+
+		result := configErrors(t, err)
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("%s:\ngiven %v\ngot %v\nexpected %v\n%v", c.name, c.contents, result, c.expected, err)
+		}
+	}
+}
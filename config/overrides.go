@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ResolutionOverride is the same as Resolution but only the values that are
+// set are used
+type ResolutionOverride struct {
+	Strategy      ResolutionStrategy `json:"strategy" description:"How links are written when fixing them, relative to the file or relative to the root"`
+	KeepExtension *bool              `json:"keepExtension" description:"Keep the .md extension when fixing links"`
+}
+
+// Override changes the resolution and rules for the files that match a
+// pattern. When several overrides match a file they are applied in order
+type Override struct {
+	Files      string             `json:"files" description:"Gitignore style pattern for the files that the override applies to, relative to the config file"`
+	Resolution ResolutionOverride `json:"resolution" description:"How links in the matching files are resolved and written"`
+	Rules      Rules              `json:"rules" description:"Severity of the lint rules for the matching files"`
+
+	// directory that the pattern is relative to
+	dir  string
+	glob Glob
+}
+
+// An override applies to a file if the pattern matches the file or any of the
+// directories containing it
+func (o Override) matches(p string) bool {
+	rel, err := filepath.Rel(o.dir, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+
+	for rel != "." {
+		if o.glob.Match(rel) {
+			return true
+		}
+
+		rel = filepath.Dir(rel)
+	}
+
+	return false
+}
+
+func (c Config) override(o Override) Config {
+	if o.Resolution.Strategy != "" {
+		c.Resolution.Strategy = o.Resolution.Strategy
+	}
+
+	if o.Resolution.KeepExtension != nil {
+		c.Resolution.KeepExtension = *o.Resolution.KeepExtension
+	}
+
+	c.Rules = c.Rules.merge(o.Rules)
+	return c
+}
+
+func (s source) prepareOverrides(config Config) (Config, []error) {
+	errs := []error{}
+
+	for i, o := range config.Overrides {
+		field := fmt.Sprintf("overrides[%d]", i)
+
+		if o.Files == "" {
+			errs = append(errs, s.errorf(field, "expected a files pattern"))
+		} else if !validGlob(o.Files) {
+			errs = append(errs, s.errorf(field+".files", "invalid pattern %q", o.Files))
+		}
+
+		strategy := o.Resolution.Strategy
+		if strategy != "" && strategy != RootResolutionStrategy && strategy != RelativeResolutionStrategy {
+			errs = append(errs, s.errorf(field+".resolution.strategy", "invalid strategy %q, expected %q or %q", strategy, RootResolutionStrategy, RelativeResolutionStrategy))
+		}
+
+		config.Overrides[i].dir = config.Dir
+		config.Overrides[i].glob = ParseGlob(o.Files)
+	}
+
+	return config, errs
+}
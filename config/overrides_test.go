@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestOverrides(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	os.WriteFile("lynks.config.json", []byte(`{
+  "resolution": { "strategy": "root", "keepExtension": false },
+  "overrides": [
+    { "files": "blog/**", "resolution": { "strategy": "relative", "keepExtension": true } },
+    { "files": "blog/drafts", "rules": { "unresolvedLinks": "off" } },
+    { "files": "CHANGELOG.md", "rules": { "unresolvedLinks": "warn", "redirectedRemoteLinks": "error" } }
+  ]
+}`), 0o644)
+
+	config, err := Load("lynks.config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Case struct {
+		file       string
+		resolution Resolution
+		rules      Rules
+	}
+
+	defaults := defaultRules()
+
+	drafts := defaultRules()
+	drafts.UnresolvedLinks = SeverityOff
+
+	changelog := defaultRules()
+	changelog.UnresolvedLinks = SeverityWarn
+	changelog.RedirectedRemoteLinks = SeverityError
+
+	cases := []Case{
+		{"docs/a.md", Resolution{RootResolutionStrategy, false}, defaults},
+		{"blog/a.md", Resolution{RelativeResolutionStrategy, true}, defaults},
+		{"blog/drafts/a.md", Resolution{RelativeResolutionStrategy, true}, drafts},
+		{"blog/drafts.md", Resolution{RelativeResolutionStrategy, true}, defaults},
+		{"docs/CHANGELOG.md", Resolution{RootResolutionStrategy, false}, changelog},
+	}
+
+	for _, c := range cases {
+		result := config.ForFile(c.file)
+		if result.Resolution != c.resolution || result.Rules != c.rules {
+			t.Errorf("\ngiven %v\ngot %v %v\nexpected %v %v", c.file, result.Resolution, result.Rules, c.resolution, c.rules)
+		}
+	}
+}
+
+func TestOverridesErrors(t *testing.T) {
+	contents := `{
+  "overrides": [
+    { "rules": { "unresolvedLinks": "maybe" } },
+    { "files": "[docs", "resolution": { "strategy": "absolute" } }
+  ]
+}`
+
+	_, err := Load(writeConfig(t, contents))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	result := configErrors(t, err)
+	expected := []Error{
+		{Line: 3, Column: 18, Field: "overrides[0].rules.unresolvedLinks"},
+	}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("\ngot %v\nexpected %v\n%v", result, expected, err)
+	}
+
+	contents = `{
+  "overrides": [
+    { "rules": { "unresolvedLinks": "warn" } },
+    { "files": "[docs", "resolution": { "strategy": "absolute" } }
+  ]
+}`
+
+	_, err = Load(writeConfig(t, contents))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	result = configErrors(t, err)
+	expected = []Error{
+		{Line: 3, Column: 5, Field: "overrides[0]"},
+		{Line: 4, Column: 7, Field: "overrides[1].files"},
+		{Line: 4, Column: 41, Field: "overrides[1].resolution.strategy"},
+	}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("\ngot %v\nexpected %v\n%v", result, expected, err)
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// IsUrl checks if a link points at a remote page
+func IsUrl(link string) bool {
+	return strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://")
+}
+
+// A remote alias target, either a url that the rest of the link is added to
+// or a url with `{name}` or `{name:pattern}` placeholders that are filled in
+// using the segments of the rest of the link in order
+type template struct {
+	url string
+	// matches the rest of the link, nil if the url has no placeholders
+	re *regexp.Regexp
+	// the placeholders in the url in the order that they are captured
+	placeholders []string
+}
+
+// Finds the placeholders in a url, braces are counted so that patterns such
+// as `{id:\d{3}}` can use repetition
+func findPlaceholders(url string) ([]string, error) {
+	placeholders := []string{}
+	depth := 0
+	start := 0
+
+	for i, r := range url {
+		switch r {
+		case '{':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+
+		case '}':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unexpected } in %q", url)
+			}
+
+			if depth == 0 {
+				placeholders = append(placeholders, url[start:i+1])
+			}
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("unclosed placeholder in %q", url)
+	}
+
+	return placeholders, nil
+}
+
+func parseTemplate(url string) (template, error) {
+	placeholders, err := findPlaceholders(url)
+	if err != nil || len(placeholders) == 0 {
+		return template{url: url}, err
+	}
+
+	segments := []string{}
+	for i, placeholder := range placeholders {
+		name, pattern, ok := strings.Cut(placeholder[1:len(placeholder)-1], ":")
+		if name == "" {
+			return template{}, fmt.Errorf("placeholder %q has no name", placeholder)
+		}
+
+		if !ok {
+			pattern = "[^/]+"
+		}
+
+		if _, err := regexp.Compile(pattern); err != nil {
+			return template{}, fmt.Errorf("invalid pattern for {%s}: %v", name, err)
+		}
+
+		segments = append(segments, fmt.Sprintf("(?P<p%d>%s)", i, pattern))
+	}
+
+	re := regexp.MustCompile("^" + strings.Join(segments, "/") + "$")
+	return template{url: url, re: re, placeholders: placeholders}, nil
+}
+
+// templates are parsed once and shared since links are resolved often
+var templates sync.Map
+
+func cachedTemplate(url string) (template, error) {
+	if t, ok := templates.Load(url); ok {
+		return t.(template), nil
+	}
+
+	t, err := parseTemplate(url)
+	if err == nil {
+		templates.Store(url, t)
+	}
+
+	return t, err
+}
+
+// Fills in the template using the rest of a link after the alias
+func (t template) expand(rest string) (string, error) {
+	rest = strings.TrimPrefix(rest, "/")
+
+	if t.re == nil {
+		if rest == "" {
+			return t.url, nil
+		}
+
+		return strings.TrimSuffix(t.url, "/") + "/" + rest, nil
+	}
+
+	match := t.re.FindStringSubmatch(rest)
+	if match == nil {
+		return "", fmt.Errorf("%q does not match %s", rest, strings.Join(t.placeholders, "/"))
+	}
+
+	url := t.url
+	for i, placeholder := range t.placeholders {
+		url = strings.Replace(url, placeholder, match[t.re.SubexpIndex(fmt.Sprintf("p%d", i))], 1)
+	}
+
+	return url, nil
+}
+
+// RemoteAlias expands a link that uses an alias for a remote url. The result
+// is false if the longest alias matching the link is not remote, an error
+// means the link does not fit the alias' template
+func (c Config) RemoteAlias(link string) (string, bool, error) {
+	alias, rest, ok := c.matchAlias(link)
+	if !ok || !IsUrl(c.Aliases[alias]) {
+		return "", false, nil
+	}
+
+	rest, fragment, hasFragment := strings.Cut(rest, "#")
+
+	t, err := cachedTemplate(c.Aliases[alias])
+	if err != nil {
+		return "", true, err
+	}
+
+	url, err := t.expand(rest)
+	if err != nil {
+		return "", true, fmt.Errorf("%s: %v", alias, err)
+	}
+
+	if hasFragment {
+		url += "#" + fragment
+	}
+
+	return url, true, nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestRemoteAlias(t *testing.T) {
+	config := Config{
+		Root: "docs",
+		Aliases: map[string]string{
+			"@":       "./",
+			"@issues": "https://tracker.local/browse/",
+			"@jira":   "https://jira.local/browse/{key:[A-Z]+-\\d{1,5}}",
+			"@gh":     "https://github.com/{owner}/{repo}/issues/{number:\\d+}",
+			"@api":    "./api",
+		},
+	}
+
+	type Case struct {
+		link     string
+		expected string
+		ok       bool
+		err      bool
+	}
+
+	cases := []Case{
+		{"@issues/123", "https://tracker.local/browse/123", true, false},
+		{"@issues/123#comment-4", "https://tracker.local/browse/123#comment-4", true, false},
+		{"@issues", "https://tracker.local/browse/", true, false},
+		{"@jira/LYNK-42", "https://jira.local/browse/LYNK-42", true, false},
+		{"@jira/lynk-42", "", true, true},
+		{"@jira/LYNK-123456", "", true, true},
+		{"@gh/sftsrv/lynks/7", "https://github.com/sftsrv/lynks/issues/7", true, false},
+		{"@gh/sftsrv/lynks", "", true, true},
+		{"@gh/sftsrv/lynks/seven", "", true, true},
+		{"@issues-old/123", "", false, false},
+		{"@api/users.md", "", false, false},
+		{"./issues/123", "", false, false},
+	}
+
+	for _, c := range cases {
+		result, ok, err := config.RemoteAlias(c.link)
+		if result != c.expected || ok != c.ok || (err != nil) != c.err {
+			t.Errorf("\ngiven %v\ngot %v %v %v\nexpected %v %v %v", c.link, result, ok, err, c.expected, c.ok, c.err)
+		}
+	}
+
+	// remote aliases are never turned into local paths or used for local files
+	if result := config.RemoveAlias("@issues/123"); result != "@issues/123" {
+		t.Errorf("\ngiven %v\ngot %v\nexpected %v", "@issues/123", result, "@issues/123")
+	}
+
+	if result := config.AddAlias("docs/api/users.md"); result != "@api/users.md" {
+		t.Errorf("\ngiven %v\ngot %v\nexpected %v", "docs/api/users.md", result, "@api/users.md")
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	type Case struct {
+		url string
+		err bool
+	}
+
+	cases := []Case{
+		{"https://tracker.local/browse/", false},
+		{"https://tracker.local/{id}", false},
+		{"https://tracker.local/{id:\\d{3}}", false},
+		{"https://tracker.local/{id", true},
+		{"https://tracker.local/id}", true},
+		{"https://tracker.local/{}", true},
+		{"https://tracker.local/{id:[}", true},
+	}
+
+	for _, c := range cases {
+		_, err := parseTemplate(c.url)
+		if (err != nil) != c.err {
+			t.Errorf("\ngiven %v\ngot %v\nexpected error %v", c.url, err, c.err)
+		}
+	}
+}
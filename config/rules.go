@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+type Severity string
+
+const (
+	SeverityOff   Severity = "off"
+	SeverityWarn  Severity = "warn"
+	SeverityError Severity = "error"
+)
+
+var severities = []Severity{SeverityOff, SeverityWarn, SeverityError}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity := Severity(text)
+	if !slices.Contains(severities, severity) {
+		return fmt.Errorf("invalid severity %q, expected %q, %q or %q", severity, SeverityOff, SeverityWarn, SeverityError)
+	}
+
+	*s = severity
+	return nil
+}
+
+// Rules sets the severity of each problem that is reported by the linter.
+// Errors fail the lint while warnings are only reported
+type Rules struct {
+	UnresolvedLinks       Severity `json:"unresolvedLinks" description:"Links to local files that don't exist"`
+	RemoteShouldBeLocal   Severity `json:"remoteShouldBeLocal" description:"Remote links that point at files in this repository, see localUrls"`
+	BrokenRemoteLinks     Severity `json:"brokenRemoteLinks" description:"Remote links that can't be reached, only checked with lint --check-remote"`
+	RedirectedRemoteLinks Severity `json:"redirectedRemoteLinks" description:"Remote links that are redirected, only checked with lint --check-remote"`
+	AmbiguousAliases      Severity `json:"ambiguousAliases" description:"Aliases that point to the same folder or that start with another alias, only set at the top level of the config"`
+}
+
+func defaultRules() Rules {
+	return Rules{
+		UnresolvedLinks:       SeverityError,
+		RemoteShouldBeLocal:   SeverityError,
+		BrokenRemoteLinks:     SeverityError,
+		RedirectedRemoteLinks: SeverityWarn,
+		AmbiguousAliases:      SeverityWarn,
+	}
+}
+
+// Replaces the severity of each rule that is set in other
+func (r Rules) merge(other Rules) Rules {
+	merged := reflect.ValueOf(&r).Elem()
+	value := reflect.ValueOf(other)
+
+	for i := range value.NumField() {
+		if !value.Field(i).IsZero() {
+			merged.Field(i).Set(value.Field(i))
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const schemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// Schema generates a JSON Schema for the config from the Config struct, using
+// the json and description tags of each field and the default config values
+func Schema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeFor[Config](), reflect.ValueOf(defaultConfig()))
+	schema["$schema"] = schemaVersion
+	schema["title"] = "lynks config"
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func typeSchema(t reflect.Type, defaults reflect.Value) map[string]any {
+	schema := map[string]any{}
+
+	if defaults.IsValid() && !defaults.IsZero() && t.Kind() != reflect.Struct {
+		schema["default"] = defaults.Interface()
+	}
+
+	switch t {
+	case reflect.TypeFor[Duration]():
+		schema["type"] = "string"
+		schema["pattern"] = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+		return schema
+
+	case reflect.TypeFor[ResolutionStrategy]():
+		schema["type"] = "string"
+		schema["enum"] = []ResolutionStrategy{RootResolutionStrategy, RelativeResolutionStrategy}
+		return schema
+
+	case reflect.TypeFor[Extends]():
+		schema["oneOf"] = []map[string]any{
+			{"type": "string"},
+			{"type": "array", "items": map[string]any{"type": "string"}},
+		}
+		return schema
+
+	case reflect.TypeFor[Severity]():
+		schema["type"] = "string"
+		schema["enum"] = severities
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), reflect.Value{})
+
+	case reflect.Struct:
+		properties := map[string]any{}
+
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "" || name == "-" {
+				continue
+			}
+
+			fieldDefaults := reflect.Value{}
+			if defaults.IsValid() {
+				fieldDefaults = defaults.Field(i)
+			}
+
+			property := typeSchema(field.Type, fieldDefaults)
+			if description := field.Tag.Get("description"); description != "" {
+				property["description"] = description
+			}
+
+			properties[name] = property
+		}
+
+		schema["type"] = "object"
+		schema["properties"] = properties
+		schema["additionalProperties"] = false
+
+	case reflect.Map:
+		schema["type"] = "object"
+		schema["additionalProperties"] = typeSchema(t.Elem(), reflect.Value{})
+
+	case reflect.Slice:
+		schema["type"] = "array"
+		schema["items"] = typeSchema(t.Elem(), reflect.Value{})
+
+	case reflect.String:
+		schema["type"] = "string"
+
+	case reflect.Bool:
+		schema["type"] = "boolean"
+
+	case reflect.Int:
+		schema["type"] = "integer"
+
+	case reflect.Float64:
+		schema["type"] = "number"
+	}
+
+	return schema
+}
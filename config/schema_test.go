@@ -0,0 +1,25 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// The schema is committed so that editors can use it, this makes sure that it
+// is regenerated with `go generate ./...` whenever the config changes
+func TestSchemaIsUpToDate(t *testing.T) {
+	committed, err := os.ReadFile("../lynks.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generated, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(bytes.TrimSpace(committed), bytes.TrimSpace(generated)) {
+		t.Errorf("lynks.schema.json is out of date, run `go generate ./...` to update it")
+	}
+}
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Workspace is a set of docs with its own root. Anything that is not set is
+// taken from the top level of the config
+type Workspace struct {
+	Name       string     `json:"name" description:"Used to select the workspace with --workspace, defaults to the root"`
+	Root       string     `json:"root" description:"Folder from which pages in the workspace are resolved, relative to the config file. May contain * to match several folders"`
+	Resolution Resolution `json:"resolution" description:"How links are resolved and written, defaults to the top level resolution"`
+	Aliases    aliases    `json:"aliases" description:"Link prefixes and the folders (relative to the workspace root) or remote urls that they resolve to, added to the top level aliases"`
+	Ignore     []string   `json:"ignore" description:"Gitignore style patterns for paths within the workspace root that are not checked, added to the top level patterns"`
+
+	// index of the workspace in the config file, a single entry can be
+	// expanded into several workspaces if the root contains a pattern
+	index int
+}
+
+func isContained(root string, p string) bool {
+	root = filepath.Clean(root)
+	p = filepath.Clean(p)
+
+	if root == "." {
+		return !filepath.IsAbs(p) && p != ".." && !strings.HasPrefix(p, ".."+string(filepath.Separator))
+	}
+
+	return p == root || strings.HasPrefix(p, root+string(filepath.Separator))
+}
+
+// Rebases a path relative to one root so that it is relative to another,
+// remote urls are left as they are
+func rebase(from string, to string, p string) string {
+	if IsUrl(p) {
+		return p
+	}
+
+	rel, err := filepath.Rel(to, filepath.Join(from, p))
+	if err != nil {
+		return p
+	}
+
+	return "./" + filepath.ToSlash(rel)
+}
+
+// ForWorkspace returns the config for a single workspace. Aliases and local
+// urls from the top level and other workspaces are included so that links
+// between workspaces can be resolved
+func (c Config) ForWorkspace(w Workspace) Config {
+	config := c
+	config.Workspaces = nil
+	config.selected = ""
+
+	// links written from the root of a workspace are relative to its root
+	config.Dir = w.Root
+	config.Root = w.Root
+	config.Resolution = w.Resolution
+	config.Ignore = slices.Concat(c.Ignore, w.Ignore)
+
+	config.Aliases = aliases{}
+	for alias, target := range c.Aliases {
+		config.Aliases[alias] = rebase(c.Root, w.Root, target)
+	}
+
+	for _, other := range c.Workspaces {
+		if other.Root == w.Root {
+			continue
+		}
+
+		for _, alias := range sortedKeys(other.Aliases) {
+			config.Aliases[alias] = rebase(other.Root, w.Root, other.Aliases[alias])
+		}
+	}
+
+	for alias, target := range w.Aliases {
+		config.Aliases[alias] = target
+	}
+
+	config.LocalUrls = map[string]string{}
+	for prefix, local := range c.LocalUrls {
+		config.LocalUrls[prefix] = rebase(c.Root, w.Root, local)
+	}
+
+	return config
+}
+
+// ForFile returns the config of the workspace containing the file, if
+// workspaces are nested the most specific one is used, with any overrides that
+// match the file applied. The top level config is used if there are no
+// workspaces or none of them contain the file
+func (c Config) ForFile(p string) Config {
+	found := -1
+
+	for i, w := range c.Workspaces {
+		if isContained(w.Root, p) && (found == -1 || len(w.Root) > len(c.Workspaces[found].Root)) {
+			found = i
+		}
+	}
+
+	config := c
+	if found != -1 {
+		config = c.ForWorkspace(c.Workspaces[found])
+	}
+
+	for _, o := range c.Overrides {
+		if o.matches(p) {
+			config = config.override(o)
+		}
+	}
+
+	return config
+}
+
+// The name of a workspace as well as the path of its root can be used to
+// select it
+func (c Config) workspaceNames(w Workspace) []string {
+	return []string{w.Name, filepath.ToSlash(c.Rel(w.Root))}
+}
+
+// WorkspaceNames are the names and roots that the workspaces can be selected by
+func (c Config) WorkspaceNames() []string {
+	names := []string{}
+	for _, w := range c.Workspaces {
+		for _, name := range c.workspaceNames(w) {
+			if name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// Select limits the workspaces that are checked to the ones with the given
+// name, other workspaces are still used to resolve links. An empty name
+// selects all workspaces
+func (c Config) Select(name string) (Config, error) {
+	if name == "" {
+		return c, nil
+	}
+
+	if len(c.Workspaces) == 0 {
+		return c, fmt.Errorf("unknown workspace %q, there are no workspaces in the config", name)
+	}
+
+	names := []string{}
+	for _, w := range c.Workspaces {
+		if slices.Contains(c.workspaceNames(w), name) {
+			c.selected = name
+			return c, nil
+		}
+
+		names = append(names, w.Name)
+	}
+
+	slices.Sort(names)
+	return c, fmt.Errorf("unknown workspace %q, expected one of %s", name, strings.Join(slices.Compact(names), ", "))
+}
+
+// Targets returns the config of every selected workspace, or the config
+// itself if there are no workspaces
+func (c Config) Targets() []Config {
+	if len(c.Workspaces) == 0 {
+		return []Config{c}
+	}
+
+	targets := []Config{}
+	for _, w := range c.Workspaces {
+		if c.selected == "" || slices.Contains(c.workspaceNames(w), c.selected) {
+			targets = append(targets, c.ForWorkspace(w))
+		}
+	}
+
+	return targets
+}
+
+// Expands the workspaces in the config, roots are made relative to the working
+// directory and workspaces inherit the top level resolution unless they
+// override it
+func (s source) expandWorkspaces(config Config) (Config, []error) {
+	errs := []error{}
+	raw, _ := s.values["workspaces"].([]any)
+
+	expanded := []Workspace{}
+	for i, w := range config.Workspaces {
+		field := fmt.Sprintf("workspaces[%d]", i)
+		w.index = i
+
+		resolution := config.Resolution
+		if object, ok := raw[i].(map[string]any); ok && object["resolution"] != nil {
+			data, _ := json.Marshal(object["resolution"])
+			json.Unmarshal(data, &resolution)
+		}
+
+		w.Resolution = resolution
+
+		if w.Root == "" {
+			errs = append(errs, s.errorf(field, "expected a root"))
+			continue
+		}
+
+		root := w.Root
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(config.Dir, root)
+		}
+
+		roots := []string{root}
+		if strings.ContainsAny(root, "*?[") {
+			matches, err := filepath.Glob(root)
+			if err != nil {
+				errs = append(errs, s.errorf(field+".root", "invalid pattern %q", w.Root))
+				continue
+			}
+
+			roots = slices.DeleteFunc(matches, func(match string) bool { return !isDir(match) })
+			if len(roots) == 0 {
+				errs = append(errs, s.errorf(field+".root", "no directories match %q", w.Root))
+				continue
+			}
+		}
+
+		name := w.Name
+		for _, root := range roots {
+			w.Root = root
+			w.Name = name
+			if w.Name == "" {
+				w.Name = filepath.ToSlash(config.Rel(root))
+			}
+
+			expanded = append(expanded, w)
+		}
+	}
+
+	config.Workspaces = expanded
+	return config, errs
+}
+
+func (s source) validateWorkspace(w Workspace) []error {
+	errs := []error{}
+	field := fmt.Sprintf("workspaces[%d]", w.index)
+
+	strategy := w.Resolution.Strategy
+	if strategy != RootResolutionStrategy && strategy != RelativeResolutionStrategy {
+		errs = append(errs, s.errorf(field+".resolution.strategy", "invalid strategy %q, expected %q or %q", strategy, RootResolutionStrategy, RelativeResolutionStrategy))
+	}
+
+	if !isDir(w.Root) {
+		errs = append(errs, s.errorf(field+".root", "directory %q does not exist", w.Root))
+	}
+
+	for _, alias := range sortedKeys(w.Aliases) {
+		if err := checkAlias(w.Root, w.Aliases[alias]); err != nil {
+			errs = append(errs, s.errorf(joinField(field+".aliases", alias), "%v", err))
+		}
+	}
+
+	for i, pattern := range w.Ignore {
+		if !validGlob(strings.TrimPrefix(pattern, "!")) {
+			errs = append(errs, s.errorf(fmt.Sprintf("%s.ignore[%d]", field, i), "invalid pattern %q", pattern))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+const workspacesConfig = `{
+  "resolution": { "strategy": "root", "keepExtension": false },
+  "aliases": { "@shared": "./shared" },
+  "workspaces": [
+    { "root": "./docs", "aliases": { "@api": "./api" } },
+    { "name": "packages", "root": "./packages/*/docs", "resolution": { "strategy": "relative" } },
+    { "root": "./website/blog", "ignore": ["drafts"] }
+  ]
+}`
+
+func loadWorkspaces(t *testing.T) Config {
+	t.Helper()
+
+	t.Chdir(t.TempDir())
+
+	for _, dir := range []string{"shared", "docs/api", "packages/a/docs", "packages/b/docs", "packages/c", "website/blog"} {
+		os.MkdirAll(dir, 0o755)
+	}
+
+	os.WriteFile("lynks.config.json", []byte(workspacesConfig), 0o644)
+
+	config, err := Load("lynks.config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return config
+}
+
+func TestWorkspaces(t *testing.T) {
+	config := loadWorkspaces(t)
+
+	type Case struct {
+		root       string
+		name       string
+		resolution Resolution
+	}
+
+	expected := []Case{
+		{"docs", "docs", Resolution{RootResolutionStrategy, false}},
+		{"packages/a/docs", "packages", Resolution{RelativeResolutionStrategy, false}},
+		{"packages/b/docs", "packages", Resolution{RelativeResolutionStrategy, false}},
+		{"website/blog", "website/blog", Resolution{RootResolutionStrategy, false}},
+	}
+
+	result := []Case{}
+	for _, w := range config.Workspaces {
+		result = append(result, Case{w.Root, w.Name, w.Resolution})
+	}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("\ngot %v\nexpected %v", result, expected)
+	}
+}
+
+func TestWorkspacesForFile(t *testing.T) {
+	config := loadWorkspaces(t)
+
+	type Case struct {
+		file     string
+		link     string
+		expected string
+	}
+
+	// aliases from the top level and other workspaces are available everywhere
+	cases := []Case{
+		{"docs/a.md", "@api/users.md", "docs/api/users.md"},
+		{"docs/a.md", "@shared/b.md", "shared/b.md"},
+		{"website/blog/post.md", "@api/users.md", "docs/api/users.md"},
+		{"packages/a/docs/c.md", "@shared/b.md", "shared/b.md"},
+		{"README.md", "@shared/b.md", "shared/b.md"},
+	}
+
+	for _, c := range cases {
+		result := config.ForFile(c.file).RemoveAlias(c.link)
+		if result != c.expected {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c, result, c.expected)
+		}
+	}
+
+	if root := config.ForFile("website/blog/post.md").Root; root != "website/blog" {
+		t.Errorf("expected the blog workspace, got %v", root)
+	}
+
+	if ignore := config.ForFile("website/blog/post.md").Ignore; !slices.Equal(ignore, []string{"drafts"}) {
+		t.Errorf("expected the blog ignore patterns, got %v", ignore)
+	}
+}
+
+func TestWorkspacesSelect(t *testing.T) {
+	config := loadWorkspaces(t)
+
+	type Case struct {
+		name     string
+		expected []string
+	}
+
+	cases := []Case{
+		{"", []string{"docs", "packages/a/docs", "packages/b/docs", "website/blog"}},
+		{"packages", []string{"packages/a/docs", "packages/b/docs"}},
+		{"packages/b/docs", []string{"packages/b/docs"}},
+	}
+
+	for _, c := range cases {
+		selected, err := config.Select(c.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := []string{}
+		for _, target := range selected.Targets() {
+			result = append(result, target.Root)
+		}
+
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.name, result, c.expected)
+		}
+	}
+
+	_, err := config.Select("unknown")
+	if err == nil {
+		t.Errorf("expected an error for an unknown workspace")
+	}
+}
+
+func TestWorkspacesErrors(t *testing.T) {
+	contents := `{
+  "workspaces": [
+    { "root": "./missing" },
+    { "root": "./missing/*/docs" },
+    { "name": "no root" }
+  ]
+}`
+
+	_, err := Load(writeConfig(t, contents))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	result := configErrors(t, err)
+	expected := []Error{
+		{Line: 3, Column: 7, Field: "workspaces[0].root"},
+		{Line: 4, Column: 7, Field: "workspaces[1].root"},
+		{Line: 5, Column: 5, Field: "workspaces[2]"},
+	}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("\ngot %v\nexpected %v\n%v", result, expected, err)
+	}
+}
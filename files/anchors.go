@@ -0,0 +1,82 @@
+package files
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Slug converts a heading into an anchor the same way GitHub does. Letters are
+// lowercased, spaces become hyphens and any other punctuation is removed
+func Slug(heading string) string {
+	slug := strings.Builder{}
+
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsNumber(r), r == '-', r == '_':
+			slug.WriteRune(r)
+
+		case r == ' ':
+			slug.WriteRune('-')
+		}
+	}
+
+	return slug.String()
+}
+
+// Anchors are the slugs of all the headings in a file. Duplicate headings get
+// a numbered suffix, e.g. `#usage` and `#usage-1`
+func Anchors(headings []string) []string {
+	seen := map[string]int{}
+	anchors := []string{}
+
+	for _, heading := range headings {
+		slug := Slug(heading)
+
+		count, ok := seen[slug]
+		seen[slug] = count + 1
+
+		if ok {
+			slug = fmt.Sprintf("%s-%d", slug, count)
+		}
+
+		anchors = append(anchors, slug)
+	}
+
+	return anchors
+}
+
+// ParseHeadings finds the text of all headings in markdown contents
+func ParseHeadings(contents string) []string {
+	headings := []string{}
+	for _, match := range headingRe.FindAllStringSubmatch(contents, -1) {
+		headings = append(headings, match[1])
+	}
+
+	return headings
+}
+
+// Heading is a heading along with its anchor and the byte offset of the line
+// it starts on
+type Heading struct {
+	Text   string
+	Anchor string
+	Offset int
+}
+
+// FindHeadings finds all headings in markdown contents along with their anchors
+func FindHeadings(contents string) []Heading {
+	matches := headingRe.FindAllStringSubmatchIndex(contents, -1)
+
+	texts := []string{}
+	for _, m := range matches {
+		texts = append(texts, contents[m[2]:m[3]])
+	}
+
+	headings := []Heading{}
+	for i, anchor := range Anchors(texts) {
+		headings = append(headings, Heading{Text: texts[i], Anchor: anchor, Offset: matches[i][0]})
+	}
+
+	return headings
+}
@@ -0,0 +1,46 @@
+package files
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAnchors(t *testing.T) {
+	headings := []string{
+		"Getting Started",
+		"The `config` file",
+		"What's new?",
+		"Usage",
+		"Usage",
+		"snake_case and kebab-case",
+	}
+
+	expected := []string{
+		"getting-started",
+		"the-config-file",
+		"whats-new",
+		"usage",
+		"usage-1",
+		"snake_case-and-kebab-case",
+	}
+
+	result := Anchors(headings)
+	if !slices.Equal(result, expected) {
+		t.Errorf("\ngiven %v\ngot %v\nexpected %v", headings, result, expected)
+	}
+}
+
+func TestFindHeadings(t *testing.T) {
+	contents := "# Intro\n\ntext\n\n## Usage\n\n## Usage\n"
+
+	expected := []Heading{
+		{Text: "Intro", Anchor: "intro", Offset: 0},
+		{Text: "Usage", Anchor: "usage", Offset: 15},
+		{Text: "Usage", Anchor: "usage-1", Offset: 25},
+	}
+
+	result := FindHeadings(contents)
+	if !slices.Equal(result, expected) {
+		t.Errorf("\ngiven %v\ngot %v\nexpected %v", contents, result, expected)
+	}
+}
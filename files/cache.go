@@ -0,0 +1,203 @@
+package files
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/sftsrv/lynks/config"
+)
+
+const CacheDir = ".lynks/cache"
+const cacheFile = "index.gob"
+
+// Bump this whenever the structure of the cache changes so old caches are discarded
+const cacheVersion = 3
+
+// File contents are not cached, only what was parsed from them
+type cacheEntry struct {
+	Size     int64
+	ModTime  time.Time
+	Hash     string
+	Headings []string
+	Links    []Link
+}
+
+type cache struct {
+	Version int
+	// A cache is only valid for the config that it was created with
+	ConfigHash string
+	Entries    map[RelativePath]cacheEntry
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func configHash(config config.Config) string {
+	data, _ := json.Marshal(config)
+	return hash(data)
+}
+
+func cachePath(config config.Config) string {
+	return filepath.Join(config.Dir, CacheDir, cacheFile)
+}
+
+func loadCache(config config.Config) cache {
+	empty := cache{
+		Version:    cacheVersion,
+		ConfigHash: configHash(config),
+		Entries:    map[RelativePath]cacheEntry{},
+	}
+
+	data, err := os.ReadFile(cachePath(config))
+	if err != nil {
+		return empty
+	}
+
+	loaded := cache{}
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&loaded)
+	if err != nil || loaded.Version != empty.Version || loaded.ConfigHash != empty.ConfigHash || loaded.Entries == nil {
+		return empty
+	}
+
+	return loaded
+}
+
+func saveCache(config config.Config, c cache) error {
+	data := bytes.Buffer{}
+	err := gob.NewEncoder(&data).Encode(c)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(cachePath(config)), 0o755)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so a concurrent run never sees half a cache
+	tmp := cachePath(config) + ".tmp"
+	err = os.WriteFile(tmp, data.Bytes(), 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, cachePath(config))
+}
+
+// ReadFilesCached is the same as ReadFiles but reuses the results from the
+// on-disk cache for any files that have not changed since the previous run.
+// Files are considered unchanged if their size and modification time match, or
+// if their content hash matches when only the modification time has changed.
+// Links can point at files that weren't given, e.g. outside of the root, so
+// cached links are always resolved again
+func ReadFilesCached(config config.Config, paths []RelativePath) []ParsedFile {
+	previous := loadCache(config)
+
+	// only write the cache back if something has changed
+	changed := atomic.Bool{}
+
+	results := make([]ParsedFile, len(paths))
+	entries := make([]cacheEntry, len(paths))
+	stats := &statCache{}
+
+	parallel(len(paths), func(i int) {
+		path := paths[i]
+
+		stat, err := os.Stat(string(path))
+		if err != nil {
+			results[i] = ParsedFile{File: File{Path: path}, Err: err}
+			changed.Store(true)
+			return
+		}
+
+		entry, ok := previous.Entries[path]
+		if ok && entry.Size == stat.Size() && entry.ModTime.Equal(stat.ModTime()) {
+			results[i] = fromCache(config, path, entry, stats.isFile)
+			entries[i] = entry
+			return
+		}
+
+		buf, err := os.ReadFile(string(path))
+		if err != nil {
+			results[i] = ParsedFile{File: File{Path: path}, Err: err}
+			changed.Store(true)
+			return
+		}
+
+		contentHash := hash(buf)
+		if ok && entry.Hash == contentHash {
+			entry.Size = stat.Size()
+			entry.ModTime = stat.ModTime()
+			changed.Store(true)
+
+			results[i] = fromCache(config, path, entry, stats.isFile)
+			entries[i] = entry
+			return
+		}
+
+		changed.Store(true)
+		file, links := parseFile(config, path, string(buf), stats.isFile)
+		results[i] = ParsedFile{File: file, Links: links}
+		entries[i] = cacheEntry{
+			Size:     stat.Size(),
+			ModTime:  stat.ModTime(),
+			Hash:     contentHash,
+			Headings: file.Headings,
+			Links:    links,
+		}
+	})
+
+	if !changed.Load() {
+		return results
+	}
+
+	// entries for files that weren't given are kept, e.g. when only linting
+	// some of the files, unless the files no longer exist
+	next := cache{
+		Version:    cacheVersion,
+		ConfigHash: previous.ConfigHash,
+		Entries:    map[RelativePath]cacheEntry{},
+	}
+
+	for path, entry := range previous.Entries {
+		if _, err := os.Stat(string(path)); err == nil {
+			next.Entries[path] = entry
+		}
+	}
+
+	for i, path := range paths {
+		if results[i].Err == nil {
+			next.Entries[path] = entries[i]
+		} else {
+			delete(next.Entries, path)
+		}
+	}
+
+	// failing to write the cache only means the next run will be slower
+	saveCache(config, next)
+
+	return results
+}
+
+// The files that links point to may have been added or removed since the links
+// were cached so they are resolved again
+func fromCache(config config.Config, path RelativePath, entry cacheEntry, isFile fileCheck) ParsedFile {
+	links := slices.Clone(entry.Links)
+
+	config = config.ForFile(string(path))
+	for i, link := range links {
+		links[i].Status, links[i].Resolved = resolveLink(config, string(path), link.Url, isFile)
+	}
+
+	return ParsedFile{File: newFile(path, "", entry.Headings, links), Links: links}
+}
@@ -0,0 +1,80 @@
+package files
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func TestReadFilesCached(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	config := config.Config{Dir: ".", Root: "./", Cache: true}
+
+	testutil.WriteFile(t, "a.md", "# Title\n\n[b](b.md)")
+	testutil.WriteFile(t, "b.md", "no links")
+
+	paths := []RelativePath{"a.md", "b.md"}
+	first := ReadFilesCached(config, paths)
+
+	if _, err := os.Stat(cachePath(config)); err != nil {
+		t.Fatalf("expected cache to be written: %v", err)
+	}
+
+	second := ReadFilesCached(config, paths)
+	if len(second[0].Links) != 1 || second[0].File.HasUnresolvedLinks {
+		t.Errorf("expected cached links to match, got %v", second[0].Links)
+	}
+
+	if len(second[0].File.Headings) != 1 || second[0].File.Headings[0] != first[0].File.Headings[0] {
+		t.Errorf("expected cached headings to match, got %v", second[0].File.Headings)
+	}
+
+	// removing a file changes the tree so cached links need to be resolved again
+	os.Remove("b.md")
+	third := ReadFilesCached(config, []RelativePath{"a.md"})
+	if !third[0].File.HasUnresolvedLinks {
+		t.Errorf("expected link to be unresolved after removing b.md")
+	}
+
+	testutil.WriteFile(t, "a.md", "[c](c.md) [d](d.md)")
+	fourth := ReadFilesCached(config, []RelativePath{"a.md"})
+	if len(fourth[0].Links) != 2 {
+		t.Errorf("expected changed file to be parsed again, got %v", fourth[0].Links)
+	}
+}
+
+func TestReadFilesCachedSubset(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	config := config.Config{Dir: ".", Root: "./", Cache: true}
+
+	testutil.WriteFile(t, "a.md", "[b](b.md)")
+	testutil.WriteFile(t, "b.md", "[a](a.md)")
+	testutil.WriteFile(t, "c.md", "no links")
+
+	ReadFilesCached(config, []RelativePath{"a.md", "b.md", "c.md"})
+	ReadFilesCached(config, []RelativePath{"a.md"})
+
+	// the target of a link may not be one of the files that are read
+	os.Remove("b.md")
+	result := ReadFilesCached(config, []RelativePath{"a.md"})
+	if !result[0].File.HasUnresolvedLinks {
+		t.Errorf("expected link to be unresolved after removing b.md, got %v", result[0].Links)
+	}
+
+	// reading some of the files keeps the others, but not removed files
+	testutil.WriteFile(t, "a.md", "changed")
+	ReadFilesCached(config, []RelativePath{"a.md"})
+
+	entries := loadCache(config).Entries
+	if _, ok := entries["c.md"]; !ok {
+		t.Errorf("expected c.md to still be cached, got %v", entries)
+	}
+
+	if _, ok := entries["b.md"]; ok {
+		t.Errorf("expected removed b.md to no longer be cached, got %v", entries)
+	}
+}
@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
-	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	lg "github.com/charmbracelet/lipgloss"
@@ -20,6 +20,8 @@ const (
 	resolved linkStatus = iota
 	unresolved
 	remote
+	// remote links to files in this repository that should use a local link
+	local
 )
 
 type RelativePath string
@@ -34,19 +36,34 @@ type Link struct {
 
 	Resolved RelativePath
 	Status   linkStatus
+
+	// byte offsets of the link in the contents of the file it's in
+	Start int
+	End   int
 }
 
 type File struct {
 	Path               RelativePath
 	Contents           string
+	Headings           []string
 	HasLinks           bool
 	HasUnresolvedLinks bool
+	UnresolvedCount    int
+}
+
+func (f File) Title() string {
+	if !f.HasUnresolvedLinks {
+		return string(f.Path)
+	}
+
+	return string(f.Path) + " " + theme.Warn.Render(fmt.Sprintf("(%d unresolved)", f.UnresolvedCount))
 }
 
 var color = map[linkStatus]lg.Color{
 	remote:     theme.ColorSecondary,
 	resolved:   theme.ColorSecondary,
 	unresolved: theme.ColorWarn,
+	local:      theme.ColorWarn,
 }
 
 func (l Link) Title() string {
@@ -54,36 +71,73 @@ func (l Link) Title() string {
 }
 
 func (l Link) FileName() string {
-	parts := strings.Split(l.Url, "/")
+	url, _, _ := strings.Cut(l.Url, "#")
+	parts := strings.Split(url, "/")
 	return parts[len(parts)-1]
 }
 
+// IsResolved is true for local links to files that exist
+func (l Link) IsResolved() bool {
+	return l.Status == resolved
+}
+
 func (l Link) IsUnresolved() bool {
 	return l.Status == unresolved
 }
 
+func (l Link) IsRemote() bool {
+	return l.Status == remote
+}
+
+func (l Link) ShouldBeLocal() bool {
+	return l.Status == local
+}
+
 func ResolveLink(config config.Config, relative string, url string) (linkStatus, RelativePath) {
+	return resolveLink(config, relative, url, isFile)
+}
+
+func resolveLink(config config.Config, relative string, url string, isFile fileCheck) (linkStatus, RelativePath) {
+	// links using a remote alias are checked the same as the url they expand
+	// to, links that don't fit the alias' template can't be resolved
+	if expanded, ok, err := config.RemoteAlias(url); ok {
+		if err != nil {
+			return unresolved, RelativePath(url)
+		}
+
+		url = expanded
+	}
+
 	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		if p, ok := config.ToLocal(url); ok {
+			return local, RelativePath(p)
+		}
+
 		return remote, RelativePath(url)
 	}
 
+	// the anchor doesn't change which file is linked to, and a link to only an
+	// anchor is to the file it's in
+	url, _, _ = strings.Cut(url, "#")
+	if url == "" {
+		return resolved, RelativePath(relative)
+	}
+
 	p := url
 	if !strings.HasSuffix(p, mdExtension) {
 		p = url + mdExtension
 	}
 
-	if strings.HasPrefix(p, "../") {
+	if strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") {
 		p = filepath.Join(filepath.Dir(relative), p)
+	} else if unaliased := config.RemoveAlias(p); unaliased != p {
+		p = unaliased
 	} else {
-		p = config.RemoveAlias(p)
-	}
-
-	stat, statErr := os.Stat(p)
-	if statErr != nil {
-		return unresolved, RelativePath(p)
+		// other links are relative to the config directory
+		p = filepath.Join(config.Dir, p)
 	}
 
-	if stat.IsDir() {
+	if !isFile(p) {
 		return unresolved, RelativePath(p)
 	}
 
@@ -91,11 +145,15 @@ func ResolveLink(config config.Config, relative string, url string) (linkStatus,
 }
 
 func FixLink(config config.Config, file File, link Link, p RelativePath) File {
-	oldLink := fmt.Sprintf("[%s](%s)", link.Name, link.Url)
+	config = config.ForFile(string(file.Path))
 
-	strategy := resolutionStrategies[config.Resolution.Strategy]
+	oldLink := fmt.Sprintf("[%s](%s)", link.Name, link.Url)
+	newPath := LinkTo(config, file.Path, p)
 
-	newPath := strategy.toMarkdownLink(config.Resolution, string(file.Path), string(p), config.AddAlias(string(p)))
+	// the link still points at the same heading in the new file
+	if _, fragment, ok := strings.Cut(link.Url, "#"); ok {
+		newPath += "#" + fragment
+	}
 
 	newLink := fmt.Sprintf("[%s](%s)", link.Name, newPath)
 
@@ -103,42 +161,64 @@ func FixLink(config config.Config, file File, link Link, p RelativePath) File {
 	return file
 }
 
-func isIgnoredPath(config config.Config, p string) bool {
-	for _, ignore := range config.Ignore {
-		fullPath := path.Join(config.Root, p)
-		fullIgnore := path.Join(config.Root, ignore)
+// LinkTo is the url a link from one file to another should use based on the
+// resolution config of the file that contains the link
+func LinkTo(config config.Config, from RelativePath, to RelativePath) string {
+	config = config.ForFile(string(from))
+	strategy := resolutionStrategies[config.Resolution.Strategy]
 
-		if strings.HasPrefix(fullPath, fullIgnore) {
-			return true
-		}
+	// links are written relative to the config directory
+	fromPath := config.Rel(string(from))
+	toPath := string(to)
+	toAlias := config.AddAlias(toPath)
+	if toAlias == toPath {
+		toPath = config.Rel(toPath)
+		toAlias = toPath
 	}
 
-	return false
+	return strategy.toMarkdownLink(config.Resolution, fromPath, toPath, toAlias)
 }
 
 func GetMarkdownFiles(config config.Config) []RelativePath {
 	var files []RelativePath
 
-	root := config.Root
-	filepath.WalkDir(root,
-		func(s string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
+	ignore := newIgnorer(config)
+	targets := config.Targets()
 
-			if isIgnoredPath(config, s) {
-				return nil
-			}
+	for _, target := range targets {
+		root := target.Root
+		filepath.WalkDir(root,
+			func(s string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				// parents have already been checked since ignored directories are
+				// skipped, nested workspaces are walked separately
+				if s != root && (ignore.matches(s, d.IsDir()) || ignore.isRoot(s)) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+
+					return nil
+				}
 
-			if !d.IsDir() && strings.HasSuffix(s, mdExtension) {
-				files = append(files, RelativePath(s))
-			}
+				if !d.IsDir() && strings.HasSuffix(s, mdExtension) {
+					files = append(files, RelativePath(s))
+				}
+
+				return nil
+			},
+		)
+	}
 
-			return nil
-		},
-	)
+	if len(targets) == 1 {
+		return files
+	}
 
-	return files
+	// workspaces may overlap so files can be found more than once
+	slices.Sort(files)
+	return slices.Compact(files)
 }
 
 func UpdateFile(resolution config.Resolution, file File) {
@@ -158,41 +238,66 @@ func UpdateFile(resolution config.Resolution, file File) {
 	}
 }
 
-func ReadFile(config config.Config, path RelativePath) (File, []Link) {
+var linkRe = regexp.MustCompile(`(\s|^)\[.+?\]\(.+?\)`)
+var nameRe = regexp.MustCompile(`\[.+?\]`)
+var urlRe = regexp.MustCompile(`\(.+?\)`)
+var headingRe = regexp.MustCompile(`(?m)^#{1,6}[ \t]+(.+?)[ \t#]*$`)
+
+// ReadFile reads a file from disk and resolves its links
+func ReadFile(config config.Config, path RelativePath) (File, []Link, error) {
+	return readFile(config, path, isFile)
+}
+
+func readFile(config config.Config, path RelativePath, isFile fileCheck) (File, []Link, error) {
 	buf, err := os.ReadFile(string(path))
 	if err != nil {
-		panic(err)
+		return File{Path: path}, nil, err
 	}
 
-	contents := string(buf)
-	linkRe := regexp.MustCompile(`(\s|^)\[.+?\]\(.+?\)`)
-	nameRe := regexp.MustCompile(`\[.+?\]`)
-	urlRe := regexp.MustCompile(`\(.+?\)`)
+	file, links := parseFile(config, path, string(buf), isFile)
+	return file, links, nil
+}
+
+func parseFile(config config.Config, path RelativePath, contents string, isFile fileCheck) (File, []Link) {
+	config = config.ForFile(string(path))
 
-	matches := linkRe.FindAllString(contents, -1)
+	matches := linkRe.FindAllStringIndex(contents, -1)
 	links := []Link{}
 
-	hasUnresolvedLinks := false
+	for _, m := range matches {
+		// the match may start with the whitespace before the link
+		start := m[0] + strings.Index(contents[m[0]:m[1]], "[")
+		match := contents[start:m[1]]
 
-	for _, match := range matches {
 		namePart := nameRe.FindString(match)
 		urlPart := urlRe.FindString(match)
 
 		if namePart != "" && urlPart != "" {
 			name := namePart[1 : len(namePart)-1]
 			url := urlPart[1 : len(urlPart)-1]
-			status, resolved := ResolveLink(config, string(path), url)
+			status, resolved := resolveLink(config, string(path), url, isFile)
 
-			link := Link{Name: name, Url: url, Resolved: resolved, Status: status}
-
-			links = append(links, link)
-			if link.IsUnresolved() {
-				hasUnresolvedLinks = true
-			}
+			links = append(links, Link{Name: name, Url: url, Resolved: resolved, Status: status, Start: start, End: m[1]})
 		}
 	}
 
-	hasLinks := len(links) > 0
+	return newFile(path, contents, ParseHeadings(contents), links), links
+}
+
+func newFile(path RelativePath, contents string, headings []string, links []Link) File {
+	unresolvedCount := 0
+	for _, link := range links {
+		if link.IsUnresolved() {
+			unresolvedCount++
+		}
+	}
 
-	return File{Path: path, Contents: contents, HasLinks: hasLinks, HasUnresolvedLinks: hasUnresolvedLinks}, links
+	return File{
+		Path:               path,
+		Contents:           contents,
+		Headings:           headings,
+		HasLinks:           len(links) > 0,
+		HasUnresolvedLinks: unresolvedCount > 0,
+		UnresolvedCount:    unresolvedCount,
+	}
 }
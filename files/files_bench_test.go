@@ -0,0 +1,93 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+)
+
+// Creates a tree of markdown files in which every file links to a few of its
+// neighbours, some of which do not exist
+func benchTree(b *testing.B, count int) []RelativePath {
+	b.Helper()
+	b.Chdir(b.TempDir())
+
+	paths := []RelativePath{}
+	for i := range count {
+		dir := fmt.Sprintf("section-%d", i%20)
+		err := os.MkdirAll(dir, 0o755)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		contents := strings.Builder{}
+		fmt.Fprintf(&contents, "# Page %d\n\n", i)
+		for j := range 10 {
+			fmt.Fprintf(&contents, "Some text with a [link %d](section-%d/page-%d.md) in it\n", j, (i+j)%20, i+j)
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("page-%d.md", i))
+		err = os.WriteFile(path, []byte(contents.String()), 0o644)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		paths = append(paths, RelativePath(path))
+	}
+
+	return paths
+}
+
+func BenchmarkGetMarkdownFiles(b *testing.B) {
+	benchTree(b, 1000)
+	config := config.Config{Root: "./"}
+
+	for b.Loop() {
+		GetMarkdownFiles(config)
+	}
+}
+
+func BenchmarkReadFileSequential(b *testing.B) {
+	paths := benchTree(b, 1000)
+	config := config.Config{Root: "./"}
+
+	for b.Loop() {
+		for _, path := range paths {
+			ReadFile(config, path)
+		}
+	}
+}
+
+func BenchmarkReadFiles(b *testing.B) {
+	paths := benchTree(b, 1000)
+	config := config.Config{Root: "./"}
+
+	for b.Loop() {
+		ReadFiles(config, paths)
+	}
+}
+
+func BenchmarkReadFilesLarge(b *testing.B) {
+	paths := benchTree(b, 10000)
+	config := config.Config{Root: "./"}
+
+	for b.Loop() {
+		ReadFiles(config, paths)
+	}
+}
+
+func BenchmarkReadFilesCached(b *testing.B) {
+	paths := benchTree(b, 1000)
+	config := config.Config{Root: "./", Cache: true}
+
+	// populate the cache so that the benchmark measures an unchanged tree
+	ReadFilesCached(config, paths)
+
+	for b.Loop() {
+		ReadFilesCached(config, paths)
+	}
+}
@@ -0,0 +1,110 @@
+package files
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func TestFixLinkForLocalUrl(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	config := config.Config{
+		Root: "./",
+		Resolution: config.Resolution{
+			Strategy:      config.RelativeResolutionStrategy,
+			KeepExtension: true,
+		},
+		LocalUrls: map[string]string{
+			"https://github.com/org/repo/blob/main/": "./",
+		},
+	}
+
+	os.Mkdir("docs", 0o755)
+	testutil.WriteFile(t, "docs/a.md", "See [b](https://github.com/org/repo/blob/main/docs/b.md#usage) for more")
+	testutil.WriteFile(t, "docs/b.md", "# Usage")
+
+	file, links, err := ReadFile(config, "docs/a.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(links) != 1 || !links[0].ShouldBeLocal() {
+		t.Fatalf("expected a link that should be local, got %v", links)
+	}
+
+	if links[0].Resolved != "docs/b.md" {
+		t.Errorf("expected link to resolve to docs/b.md, got %s", links[0].Resolved)
+	}
+
+	fixed := FixLink(config, file, links[0], links[0].Resolved)
+	if !strings.Contains(fixed.Contents, "[b](./b.md#usage)") {
+		t.Errorf("expected link to be made relative and keep its anchor, got %s", fixed.Contents)
+	}
+}
+
+func TestRemoteAliasLinks(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	config := config.Config{
+		Root: "./",
+		Aliases: map[string]string{
+			"@issues": "https://tracker.local/browse/{id:\\d+}",
+			"@repo":   "https://github.com/org/repo/blob/main/",
+		},
+		LocalUrls: map[string]string{
+			"https://github.com/org/repo/blob/main/": "./",
+		},
+	}
+
+	testutil.WriteFile(t, "a.md", "See [bug](@issues/123), [typo](@issues/abc) and [b](@repo/b.md)")
+	testutil.WriteFile(t, "b.md", "# B")
+
+	_, links, err := ReadFile(config, "a.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Case struct {
+		status   linkStatus
+		resolved RelativePath
+	}
+
+	expected := []Case{
+		{remote, "https://tracker.local/browse/123"},
+		{unresolved, "@issues/abc"},
+		{local, "b.md"},
+	}
+
+	if len(links) != len(expected) {
+		t.Fatalf("expected %d links, got %v", len(expected), links)
+	}
+
+	for i, c := range expected {
+		if links[i].Status != c.status || links[i].Resolved != c.resolved {
+			t.Errorf("\ngiven %v\ngot %v %v\nexpected %v %v", links[i].Url, links[i].Status, links[i].Resolved, c.status, c.resolved)
+		}
+	}
+}
+
+func TestLinkOffsets(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	contents := "[a](a.md) and\n[b](b.md)"
+	_, links := parseFile(config.Config{Root: "./"}, "index.md", contents, isFile)
+
+	expected := []string{"[a](a.md)", "[b](b.md)"}
+	if len(links) != len(expected) {
+		t.Fatalf("expected %d links, got %v", len(expected), links)
+	}
+
+	for i, link := range links {
+		result := contents[link.Start:link.End]
+		if result != expected[i] {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", contents, result, expected[i])
+		}
+	}
+}
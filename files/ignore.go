@@ -0,0 +1,275 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sftsrv/lynks/config"
+)
+
+const gitignoreFile = ".gitignore"
+
+// A single line of a gitignore file
+type ignorePattern struct {
+	glob    config.Glob
+	negate  bool
+	dirOnly bool
+}
+
+// Parses a pattern using gitignore rules, blank lines and comments are skipped
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	pattern := ignorePattern{}
+
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern, false
+	}
+
+	if after, ok := strings.CutPrefix(line, "!"); ok {
+		pattern.negate = true
+		line = after
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if after, ok := strings.CutSuffix(line, "/"); ok {
+		pattern.dirOnly = true
+		line = after
+	}
+
+	if strings.Trim(line, "./") == "" {
+		return pattern, false
+	}
+
+	pattern.glob = config.ParseGlob(line)
+	return pattern, true
+}
+
+func parseIgnorePatterns(lines []string) []ignorePattern {
+	patterns := []ignorePattern{}
+
+	for _, line := range lines {
+		if pattern, ok := parseIgnorePattern(line); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	return patterns
+}
+
+// Matches a path relative to where the pattern was defined
+func (p ignorePattern) match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+
+	return p.glob.Match(rel)
+}
+
+// Applies patterns in order so that later patterns win, returning whether the
+// path was matched at all and if so whether it is ignored
+func matchPatterns(patterns []ignorePattern, rel string, isDir bool) (matched bool, ignored bool) {
+	for _, pattern := range patterns {
+		if pattern.match(rel, isDir) {
+			matched = true
+			ignored = !pattern.negate
+		}
+	}
+
+	return matched, ignored
+}
+
+// Patterns from the config are relative to the root of the workspace that
+// contains the path
+type ignoreRoot struct {
+	root     string
+	patterns []ignorePattern
+	target   bool
+}
+
+// ignorer decides which paths are ignored using the patterns from the config
+// and, if enabled, the .gitignore files of the repository. Paths are matched
+// as absolute paths since they may be given relative to a different directory
+// than the one the patterns are relative to
+type ignorer struct {
+	cwd string
+
+	// ordered from the most specific root to the least specific
+	roots []ignoreRoot
+
+	useGitignore bool
+	top          string
+	gitignores   sync.Map
+}
+
+func newIgnorer(c config.Config) *ignorer {
+	cwd, _ := os.Getwd()
+
+	i := &ignorer{
+		cwd:          cwd,
+		useGitignore: c.UseGitignore,
+	}
+
+	targets := map[string]bool{}
+	for _, target := range c.Targets() {
+		targets[i.abs(target.Root)] = true
+	}
+
+	workspaces := []config.Config{c}
+	if len(c.Workspaces) > 0 {
+		workspaces = []config.Config{}
+		for _, w := range c.Workspaces {
+			workspaces = append(workspaces, c.ForWorkspace(w))
+		}
+	}
+
+	for _, w := range workspaces {
+		root := i.abs(w.Root)
+		i.roots = append(i.roots, ignoreRoot{root, parseIgnorePatterns(w.Ignore), targets[root]})
+	}
+
+	slices.SortStableFunc(i.roots, func(a ignoreRoot, b ignoreRoot) int {
+		return len(b.root) - len(a.root)
+	})
+
+	i.top = repositoryRoot(i.abs(c.Dir))
+
+	return i
+}
+
+// The directory containing `.git`, or the given directory if there is none
+func repositoryRoot(dir string) string {
+	for current := dir; ; current = filepath.Dir(current) {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+
+		if filepath.Dir(current) == current {
+			return dir
+		}
+	}
+}
+
+func (i *ignorer) abs(p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+
+	return filepath.Join(i.cwd, p)
+}
+
+// The path relative to base if it is within base
+func within(base string, p string) (string, bool) {
+	if base == p {
+		return "", false
+	}
+
+	return strings.CutPrefix(p, strings.TrimSuffix(base, string(filepath.Separator))+string(filepath.Separator))
+}
+
+func (i *ignorer) gitignore(dir string) []ignorePattern {
+	if patterns, ok := i.gitignores.Load(dir); ok {
+		return patterns.([]ignorePattern)
+	}
+
+	patterns := []ignorePattern{}
+
+	data, err := os.ReadFile(filepath.Join(dir, gitignoreFile))
+	if err == nil {
+		patterns = parseIgnorePatterns(strings.Split(string(data), "\n"))
+	}
+
+	i.gitignores.Store(dir, patterns)
+	return patterns
+}
+
+// Checks the path itself without considering whether one of its parents is
+// ignored. Patterns in deeper .gitignore files take precedence and the config
+// takes precedence over all of them
+func (i *ignorer) matches(p string, isDir bool) bool {
+	p = i.abs(p)
+	ignored := false
+
+	if i.useGitignore {
+		if filepath.Base(p) == ".git" {
+			return true
+		}
+
+		if rel, ok := within(i.top, p); ok {
+			dir := i.top
+			parts := strings.Split(rel, string(filepath.Separator))
+
+			for _, part := range parts {
+				rel, _ := within(dir, p)
+				if matched, result := matchPatterns(i.gitignore(dir), rel, isDir); matched {
+					ignored = result
+				}
+
+				dir = filepath.Join(dir, part)
+			}
+		}
+	}
+
+	if root, ok := i.rootOf(p); ok {
+		rel, _ := within(root.root, p)
+		if matched, result := matchPatterns(root.patterns, rel, isDir); matched {
+			ignored = result
+		}
+	}
+
+	return ignored
+}
+
+// The most specific root that contains the path
+func (i *ignorer) rootOf(p string) (ignoreRoot, bool) {
+	for _, root := range i.roots {
+		if _, ok := within(root.root, p); ok {
+			return root, true
+		}
+	}
+
+	return ignoreRoot{}, false
+}
+
+// Whether the path is the root of a workspace
+func (i *ignorer) isRoot(p string) bool {
+	p = i.abs(p)
+
+	return slices.ContainsFunc(i.roots, func(root ignoreRoot) bool {
+		return root.root == p
+	})
+}
+
+// A path is ignored if it matches or if any of its parent directories do,
+// since files within an ignored directory can't be included again. Paths that
+// are not within a selected workspace are also ignored
+func (i *ignorer) isIgnored(p string, isDir bool) bool {
+	p = i.abs(p)
+
+	if root, ok := i.rootOf(p); !ok || !root.target {
+		return true
+	}
+
+	parents := []string{}
+	for dir := filepath.Dir(p); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		parents = append(parents, dir)
+	}
+
+	for j := len(parents) - 1; j >= 0; j-- {
+		if i.matches(parents[j], true) {
+			return true
+		}
+	}
+
+	return i.matches(p, isDir)
+}
+
+// Whether a path is ignored when it is not known if it is a directory, paths
+// that no longer exist are treated as files
+func (i *ignorer) isIgnoredPath(p string) bool {
+	stat, err := os.Stat(p)
+	return i.isIgnored(p, err == nil && stat.IsDir())
+}
@@ -0,0 +1,116 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func TestIgnorePattern(t *testing.T) {
+	type Case struct {
+		pattern  string
+		path     string
+		isDir    bool
+		expected bool
+	}
+
+	cases := []Case{
+		{"node_modules", "node_modules", true, true},
+		{"node_modules", "node_modules_docs.md", false, false},
+		{"node_modules", "packages/a/node_modules", true, true},
+		{"*.generated.md", "docs/api.generated.md", false, true},
+		{"*.generated.md", "docs/api.md", false, false},
+		{"**/CHANGELOG.md", "CHANGELOG.md", false, true},
+		{"**/CHANGELOG.md", "packages/a/CHANGELOG.md", false, true},
+		{"/CHANGELOG.md", "packages/a/CHANGELOG.md", false, false},
+		{"docs/*.md", "docs/a.md", false, true},
+		{"docs/*.md", "docs/nested/a.md", false, false},
+		{"docs/**/*.md", "docs/nested/deeper/a.md", false, true},
+		{"./generated", "generated", true, true},
+		{"drafts/", "drafts", true, true},
+		{"drafts/", "drafts", false, false},
+		{"# comment", "# comment", false, false},
+		{`\#hash.md`, "#hash.md", false, true},
+	}
+
+	for _, c := range cases {
+		result := false
+		if pattern, ok := parseIgnorePattern(c.pattern); ok {
+			result = pattern.match(c.path, c.isDir)
+		}
+
+		if result != c.expected {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c, result, c.expected)
+		}
+	}
+}
+
+func TestGetMarkdownFilesIgnore(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	for _, dir := range []string{".git", "docs/drafts", "docs/api", "node_modules/pkg"} {
+		os.MkdirAll(dir, 0o755)
+	}
+
+	for _, file := range []string{
+		"README.md",
+		"CHANGELOG.md",
+		"node_modules_docs.md",
+		"node_modules/pkg/README.md",
+		"docs/a.md",
+		"docs/keep.generated.md",
+		"docs/other.generated.md",
+		"docs/drafts/b.md",
+		"docs/api/c.md",
+		"docs/api/d.md",
+	} {
+		testutil.WriteFile(t, file, "")
+	}
+
+	testutil.WriteFile(t, ".gitignore", "docs/api/\n!docs/api/c.md\n")
+	testutil.WriteFile(t, "docs/.gitignore", "drafts\n")
+
+	c := config.Config{
+		Root:   "./",
+		Ignore: []string{"node_modules", "**/CHANGELOG.md", "*.generated.md", "!keep.generated.md"},
+	}
+
+	result := GetMarkdownFiles(c)
+	expected := []RelativePath{
+		"README.md",
+		"docs/a.md",
+		"docs/api/c.md",
+		"docs/api/d.md",
+		"docs/drafts/b.md",
+		"docs/keep.generated.md",
+		"node_modules_docs.md",
+	}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.Ignore, result, expected)
+	}
+
+	// files within an ignored directory can't be included again
+	c.UseGitignore = true
+
+	result = GetMarkdownFiles(c)
+	expected = []RelativePath{
+		"README.md",
+		"docs/a.md",
+		"docs/keep.generated.md",
+		"node_modules_docs.md",
+	}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.Ignore, result, expected)
+	}
+
+	ignore := newIgnorer(c)
+	if !ignore.isIgnored(filepath.Join("docs", "drafts", "new.md"), false) {
+		t.Errorf("expected files in an ignored directory to be ignored")
+	}
+}
@@ -0,0 +1,195 @@
+package files
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sftsrv/lynks/config"
+)
+
+// Index holds the parsed contents of all markdown files so that changes to a
+// single file only require that file and the files linking to it to be re-read.
+// File contents may not be available when read from the cache so files should
+// be re-read before they are modified
+type Index struct {
+	config config.Config
+	ignore *ignorer
+	files  map[RelativePath]File
+	links  map[RelativePath][]Link
+}
+
+func NewIndex(config config.Config, paths []RelativePath) *Index {
+	if config.Cache {
+		return NewIndexOf(config, ReadFilesCached(config, paths))
+	}
+
+	return NewIndexOf(config, ReadFiles(config, paths))
+}
+
+// NewIndexOf creates an index of files that have already been parsed
+func NewIndexOf(config config.Config, parsed []ParsedFile) *Index {
+	index := &Index{
+		config: config,
+		ignore: newIgnorer(config),
+		files:  map[RelativePath]File{},
+		links:  map[RelativePath][]Link{},
+	}
+
+	index.add(parsed)
+
+	return index
+}
+
+func (i *Index) read(paths []RelativePath) {
+	i.add(ReadFiles(i.config, paths))
+}
+
+// Files that could not be read are left out, the same as if they didn't exist
+func (i *Index) add(parsed []ParsedFile) {
+	for _, p := range parsed {
+		if p.Err != nil {
+			i.remove(p.File.Path)
+			continue
+		}
+
+		i.files[p.File.Path] = p.File
+		i.links[p.File.Path] = p.Links
+	}
+}
+
+// Set replaces the indexed contents of files, e.g. with unsaved changes from an
+// editor. Files linking to them are not affected
+func (i *Index) Set(parsed []ParsedFile) {
+	i.add(parsed)
+}
+
+func (i *Index) remove(path RelativePath) {
+	delete(i.files, path)
+	delete(i.links, path)
+}
+
+// Paths of all indexed files in lexical order
+func (i *Index) Paths() []RelativePath {
+	paths := []RelativePath{}
+	for path := range i.files {
+		paths = append(paths, path)
+	}
+
+	slices.Sort(paths)
+	return paths
+}
+
+// Files of all indexed files in lexical order
+func (i *Index) Files() []File {
+	files := []File{}
+	for _, path := range i.Paths() {
+		files = append(files, i.files[path])
+	}
+
+	return files
+}
+
+func (i *Index) Get(path RelativePath) (File, []Link, bool) {
+	file, ok := i.files[path]
+	return file, i.links[path], ok
+}
+
+// Backlinks are the files that contain a link that resolves (or attempts to
+// resolve) to the given path
+func (i *Index) Backlinks(path RelativePath) []RelativePath {
+	target := filepath.Clean(string(path))
+	backlinks := []RelativePath{}
+
+	for _, from := range i.Paths() {
+		for _, link := range i.links[from] {
+			if filepath.Clean(string(link.Resolved)) == target {
+				backlinks = append(backlinks, from)
+				break
+			}
+		}
+	}
+
+	return backlinks
+}
+
+// Update re-reads the given paths as well as any files that link to them. Paths
+// that no longer exist are removed and directories are re-read recursively.
+// Returns all of the paths that were affected by the update
+func (i *Index) Update(paths []RelativePath) []RelativePath {
+	changed := []RelativePath{}
+
+	for _, path := range paths {
+		changed = append(changed, i.expand(path)...)
+	}
+
+	affected := []RelativePath{}
+	for _, path := range changed {
+		affected = append(affected, path)
+		affected = append(affected, i.Backlinks(path)...)
+	}
+
+	slices.Sort(affected)
+	affected = slices.Compact(affected)
+
+	existing := []RelativePath{}
+	for _, path := range affected {
+		if i.isMarkdownFile(string(path)) {
+			existing = append(existing, path)
+		} else {
+			i.remove(path)
+		}
+	}
+
+	i.read(existing)
+
+	return affected
+}
+
+// Expands a changed path into the markdown files it may have affected. For a
+// directory this is everything inside of it, both on disk and in the index
+func (i *Index) expand(path RelativePath) []RelativePath {
+	clean := RelativePath(filepath.Clean(string(path)))
+	expanded := []RelativePath{clean}
+
+	prefix := string(clean) + string(filepath.Separator)
+	for existing := range i.files {
+		if strings.HasPrefix(string(existing), prefix) {
+			expanded = append(expanded, existing)
+		}
+	}
+
+	stat, err := os.Stat(string(clean))
+	if err != nil || !stat.IsDir() {
+		return expanded
+	}
+
+	filepath.WalkDir(string(clean), func(s string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() && s != string(clean) && i.ignore.matches(s, true) {
+			return filepath.SkipDir
+		}
+
+		if i.isMarkdownFile(s) {
+			expanded = append(expanded, RelativePath(s))
+		}
+
+		return nil
+	})
+
+	return expanded
+}
+
+func (i *Index) isMarkdownFile(p string) bool {
+	if !strings.HasSuffix(p, mdExtension) {
+		return false
+	}
+
+	stat, err := os.Stat(p)
+	return err == nil && !stat.IsDir() && !i.ignore.isIgnored(p, false)
+}
@@ -0,0 +1,51 @@
+package files
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func TestIndexUpdateRereadsBacklinks(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	config := config.Config{Root: "./"}
+
+	testutil.WriteFile(t, "a.md", "[b](b.md)")
+	testutil.WriteFile(t, "b.md", "no links")
+	testutil.WriteFile(t, "c.md", "no links")
+
+	index := NewIndex(config, GetMarkdownFiles(config))
+
+	a, _, _ := index.Get("a.md")
+	if a.HasUnresolvedLinks {
+		t.Fatalf("expected a.md to be resolved before removing b.md")
+	}
+
+	os.Remove("b.md")
+	affected := index.Update([]RelativePath{"b.md"})
+
+	if !slices.Equal(affected, []RelativePath{"a.md", "b.md"}) {
+		t.Errorf("expected a.md and b.md to be affected, got %v", affected)
+	}
+
+	a, _, _ = index.Get("a.md")
+	if !a.HasUnresolvedLinks {
+		t.Errorf("expected a.md to be unresolved after removing b.md")
+	}
+
+	if _, _, ok := index.Get("b.md"); ok {
+		t.Errorf("expected b.md to be removed from the index")
+	}
+
+	testutil.WriteFile(t, "b.md", "back again")
+	index.Update([]RelativePath{"b.md"})
+
+	a, _, _ = index.Get("a.md")
+	if a.HasUnresolvedLinks {
+		t.Errorf("expected a.md to be resolved after recreating b.md")
+	}
+}
@@ -63,6 +63,11 @@ var relativeResolutionStrategy = ResolutionStrategy{
 			panic(fmt.Errorf("Received incompatible paths. Link from %s to %s", from, to))
 		}
 
+		// links without `./` or `../` are resolved from the config directory
+		if !strings.HasPrefix(rel, "../") {
+			rel = "./" + rel
+		}
+
 		if config.KeepExtension {
 			return rel
 		}
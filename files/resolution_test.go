@@ -28,7 +28,7 @@ func TestRelativeResolutionStrategyWithoutExtension(t *testing.T) {
 	cases := []Case{
 		{"my-example/uncle/cousin.md", "my-example/uncle/cousin.md", "../uncle/cousin"},
 		{"my-example/parent.md", "my-example/parent.md", "../parent"},
-		{"my-example/folder/sibling.md", "my-example/folder/sibling.md", "sibling"},
+		{"my-example/folder/sibling.md", "my-example/folder/sibling.md", "./sibling"},
 
 		// alias
 		{"my-example/folder/sibling.md", "my-alias/sibling.md", "my-alias/sibling"},
@@ -54,7 +54,7 @@ func TestRelativeResolutionStrategyWitExtension(t *testing.T) {
 	cases := []Case{
 		{"my-example/uncle/cousin.md", "my-example/uncle/cousin.md", "../uncle/cousin.md"},
 		{"my-example/parent.md", "my-example/parent.md", "../parent.md"},
-		{"my-example/folder/sibling.md", "my-example/folder/sibling.md", "sibling.md"},
+		{"my-example/folder/sibling.md", "my-example/folder/sibling.md", "./sibling.md"},
 
 		// alias
 		{"my-example/folder/sibling.md", "my-alias/sibling.md", "my-alias/sibling.md"},
@@ -0,0 +1,103 @@
+package files
+
+import (
+	"maps"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/sftsrv/lynks/config"
+)
+
+// Checks whether a path is an existing file (and not a directory)
+type fileCheck func(p string) bool
+
+func isFile(p string) bool {
+	stat, err := os.Stat(p)
+	if err != nil {
+		return false
+	}
+
+	return !stat.IsDir()
+}
+
+// Many links point at the same handful of files so stat results are memoised
+// for the duration of a scan. The cache is not invalidated so it should not
+// outlive the scan it was created for
+type statCache struct {
+	results sync.Map
+}
+
+func (c *statCache) isFile(p string) bool {
+	if result, ok := c.results.Load(p); ok {
+		return result.(bool)
+	}
+
+	result := isFile(p)
+	c.results.Store(p, result)
+
+	return result
+}
+
+type ParsedFile struct {
+	File  File
+	Links []Link
+	// set if the file could not be read, e.g. if it was removed while reading
+	Err error
+}
+
+// ReadFiles reads and resolves the given files concurrently, the results are
+// in the same order as the given paths
+func ReadFiles(config config.Config, paths []RelativePath) []ParsedFile {
+	results := make([]ParsedFile, len(paths))
+	cache := &statCache{}
+
+	parallel(len(paths), func(i int) {
+		file, links, err := readFile(config, paths[i], cache.isFile)
+		results[i] = ParsedFile{File: file, Links: links, Err: err}
+	})
+
+	return results
+}
+
+// ParseFiles resolves the links in files whose contents have already been
+// read, e.g. from the git index instead of the working tree. The results are
+// in lexical order
+func ParseFiles(config config.Config, contents map[RelativePath]string) []ParsedFile {
+	paths := slices.Sorted(maps.Keys(contents))
+	results := make([]ParsedFile, len(paths))
+	cache := &statCache{}
+
+	parallel(len(paths), func(i int) {
+		file, links := parseFile(config, paths[i], contents[paths[i]], cache.isFile)
+		results[i] = ParsedFile{File: file, Links: links}
+	})
+
+	return results
+}
+
+// Runs work for each index from 0 to count using a pool of workers
+func parallel(count int, work func(i int)) {
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+
+	workers := min(runtime.NumCPU(), count)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				work(i)
+			}
+		}()
+	}
+
+	for i := range count {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+}
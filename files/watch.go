@@ -0,0 +1,136 @@
+package files
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sftsrv/lynks/config"
+)
+
+// Editors tend to emit a burst of events for a single save so these are
+// collected and reported together once things have settled down
+const watchDebounce = 100 * time.Millisecond
+
+type Watcher struct {
+	config  config.Config
+	ignore  *ignorer
+	watcher *fsnotify.Watcher
+
+	// Batches of paths that were created, modified, removed or renamed
+	Changes chan []RelativePath
+	Errors  chan error
+}
+
+func NewWatcher(config config.Config) (*Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		config:  config,
+		ignore:  newIgnorer(config),
+		watcher: fsWatcher,
+		Changes: make(chan []RelativePath),
+		Errors:  make(chan error),
+	}
+
+	for _, target := range config.Targets() {
+		err = w.addRecursive(target.Root)
+		if err != nil {
+			fsWatcher.Close()
+			return nil, err
+		}
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+func (w *Watcher) Close() error {
+	return w.watcher.Close()
+}
+
+// fsnotify does not watch recursively so each directory needs to be added
+func (w *Watcher) addRecursive(root string) error {
+	return filepath.WalkDir(root, func(s string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if s != root && (w.ignore.matches(s, true) || w.ignore.isRoot(s)) {
+			return filepath.SkipDir
+		}
+
+		return w.watcher.Add(s)
+	})
+}
+
+// Only changes to markdown files matter, other paths are only relevant if they
+// could be directories that were created, removed or renamed
+func (w *Watcher) isRelevant(event fsnotify.Event) bool {
+	if w.ignore.isIgnoredPath(event.Name) {
+		return false
+	}
+
+	if strings.HasSuffix(event.Name, mdExtension) {
+		return event.Op != fsnotify.Chmod
+	}
+
+	return event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)
+}
+
+func (w *Watcher) run() {
+	defer close(w.Changes)
+
+	pending := map[RelativePath]bool{}
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+
+			if event.Has(fsnotify.Create) && !w.ignore.isIgnoredPath(event.Name) {
+				// new directories need to be watched too, errors here just mean
+				// that the path was a file or has already been removed again
+				w.addRecursive(event.Name)
+			}
+
+			if !w.isRelevant(event) {
+				continue
+			}
+
+			// paths are cleaned to match the index, e.g. `./a.md` is `a.md`
+			pending[RelativePath(filepath.Clean(event.Name))] = true
+			timer.Reset(watchDebounce)
+
+		case <-timer.C:
+			changes := []RelativePath{}
+			for path := range pending {
+				changes = append(changes, path)
+			}
+
+			pending = map[RelativePath]bool{}
+			w.Changes <- changes
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+
+			w.Errors <- err
+		}
+	}
+}
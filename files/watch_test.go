@@ -0,0 +1,119 @@
+package files
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+// Waits for the next batch of changes, sorted so that it can be compared
+func nextBatch(t *testing.T, w *Watcher) []RelativePath {
+	t.Helper()
+
+	select {
+	case changes := <-w.Changes:
+		slices.Sort(changes)
+		return changes
+
+	case err := <-w.Errors:
+		t.Fatal(err)
+
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for changes")
+	}
+
+	return nil
+}
+
+func TestWatcher(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFile(t, "a.md", "# A")
+
+	w, err := NewWatcher(config.Config{Root: "./", Ignore: []string{"node_modules"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer w.Close()
+
+	type Case struct {
+		given    string
+		change   func()
+		expected []RelativePath
+	}
+
+	cases := []Case{
+		{
+			"a burst of writes is reported once",
+			func() {
+				for range 5 {
+					testutil.WriteFile(t, "a.md", "# A again")
+				}
+			},
+			[]RelativePath{"a.md"},
+		},
+		{
+			"new directories are reported",
+			func() { os.Mkdir("docs", 0o755) },
+			[]RelativePath{"docs"},
+		},
+		{
+			"files in new directories are watched",
+			func() { testutil.WriteFile(t, "docs/b.md", "# B") },
+			[]RelativePath{"docs/b.md"},
+		},
+		{
+			"renames report the old and new path",
+			func() { os.Rename("docs/b.md", "docs/c.md") },
+			[]RelativePath{"docs/b.md", "docs/c.md"},
+		},
+		{
+			"removed files are reported",
+			func() { os.Remove("a.md") },
+			[]RelativePath{"a.md"},
+		},
+		{
+			"created paths are reported since they may be directories",
+			func() { testutil.WriteFile(t, "docs/notes.txt", "notes") },
+			[]RelativePath{"docs/notes.txt"},
+		},
+		{
+			"only changes to markdown files are reported",
+			func() {
+				testutil.WriteFile(t, "docs/notes.txt", "more notes")
+				testutil.WriteFile(t, "docs/c.md", "# C")
+			},
+			[]RelativePath{"docs/c.md"},
+		},
+		{
+			"ignored directories are not reported",
+			func() {
+				os.MkdirAll("node_modules/pkg/deep", 0o755)
+				testutil.WriteFile(t, "node_modules/pkg/deep/readme.md", "# Pkg")
+				testutil.WriteFile(t, "docs/c.md", "# C again")
+			},
+			[]RelativePath{"docs/c.md"},
+		},
+	}
+
+	for _, c := range cases {
+		c.change()
+
+		result := nextBatch(t, w)
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.given, result, c.expected)
+		}
+	}
+
+	for _, watched := range w.watcher.WatchList() {
+		if strings.Contains(watched, "node_modules") {
+			t.Errorf("expected ignored directories not to be watched, got %s", watched)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package files
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+func TestWorkspaceFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	for _, dir := range []string{"docs/guides", "website/blog/drafts"} {
+		os.MkdirAll(dir, 0o755)
+	}
+
+	testutil.WriteFile(t, "README.md", "")
+	testutil.WriteFile(t, "docs/index.md", "[guide](guides/setup) [post](../website/blog/post.md) [missing](nope)")
+	testutil.WriteFile(t, "docs/guides/setup.md", "[home](index)")
+	testutil.WriteFile(t, "website/blog/post.md", "[setup](@docs/guides/setup.md) [draft](./drafts/wip.md)")
+	testutil.WriteFile(t, "website/blog/drafts/wip.md", "")
+	testutil.WriteFile(t, "lynks.config.json", `{
+  "workspaces": [
+    { "root": "./docs", "resolution": { "strategy": "root" }, "aliases": { "@docs": "./" } },
+    { "name": "blog", "root": "./website/blog", "ignore": ["drafts/"] }
+  ]
+}`)
+
+	c, err := config.Load("lynks.config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := GetMarkdownFiles(c)
+	expected := []RelativePath{"docs/guides/setup.md", "docs/index.md", "website/blog/post.md"}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("\ngot %v\nexpected %v", result, expected)
+	}
+
+	// links are resolved using the workspace of the file they are in, including
+	// links to other workspaces
+	unresolved := []string{}
+	for _, parsed := range ReadFiles(c, result) {
+		for _, link := range parsed.Links {
+			if link.IsUnresolved() {
+				unresolved = append(unresolved, link.Url)
+			}
+		}
+	}
+
+	if !slices.Equal(unresolved, []string{"nope"}) {
+		t.Errorf("expected only the missing link to be unresolved, got %v", unresolved)
+	}
+
+	blog, err := c.Select("blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result = GetMarkdownFiles(blog)
+	expected = []RelativePath{"website/blog/post.md"}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("\ngot %v\nexpected %v", result, expected)
+	}
+}
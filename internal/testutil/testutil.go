@@ -0,0 +1,31 @@
+// Helpers shared by the tests of the other packages
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// WriteFile writes a file relative to the current directory, creating the
+// directories it's in
+func WriteFile(t testing.TB, path string, contents string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// WriteFiles writes each file in the map of paths to contents
+func WriteFiles(t testing.TB, files map[string]string) {
+	t.Helper()
+
+	for path, contents := range files {
+		WriteFile(t, path, contents)
+	}
+}
@@ -0,0 +1,249 @@
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sftsrv/lynks/files"
+)
+
+// Number of lines of the target shown when hovering over a link
+const previewLines = 10
+
+// Finds the link at a position in an open document
+func (s *server) linkAt(params TextDocumentPositionParams) (files.RelativePath, files.Link, bool) {
+	path, ok := s.pathOf(params.TextDocument.URI)
+	if !ok {
+		return "", files.Link{}, false
+	}
+
+	doc, ok := s.documents[path]
+	if !ok {
+		return "", files.Link{}, false
+	}
+
+	offset := offsetOf(doc.text, params.Position)
+	_, links, _ := s.index.Get(path)
+	for _, link := range links {
+		if offset >= link.Start && offset < link.End {
+			return path, link, true
+		}
+	}
+
+	return "", files.Link{}, false
+}
+
+// Finds the local file a link points to and the anchor it points to in that
+// file, if any
+func (s *server) target(link files.Link) (files.RelativePath, string, bool) {
+	if link.IsRemote() || link.IsUnresolved() {
+		return "", "", false
+	}
+
+	_, anchor, _ := strings.Cut(link.Url, "#")
+	return link.Resolved, anchor, true
+}
+
+func (s *server) resolve(path files.RelativePath, url string) (files.RelativePath, bool) {
+	status, resolved := files.ResolveLink(s.config.ForFile(string(path)), string(path), url)
+	if (files.Link{Status: status}).IsUnresolved() {
+		return "", false
+	}
+
+	return resolved, true
+}
+
+// Offset of the heading with the given anchor, or the start of the file if
+// there is no such heading
+func headingOffset(text string, anchor string) int {
+	for _, heading := range files.FindHeadings(text) {
+		if heading.Anchor == anchor {
+			return heading.Offset
+		}
+	}
+
+	return 0
+}
+
+func (s *server) definition(params TextDocumentPositionParams) *Location {
+	_, link, ok := s.linkAt(params)
+	if !ok {
+		return nil
+	}
+
+	target, anchor, ok := s.target(link)
+	if !ok {
+		return nil
+	}
+
+	text, _ := s.text(target)
+	start := positionOf(text, headingOffset(text, anchor))
+
+	return &Location{URI: s.uriOf(target), Range: Range{Start: start, End: start}}
+}
+
+// References are the links to the file, including links to its anchors
+func (s *server) references(params TextDocumentPositionParams) []Location {
+	locations := []Location{}
+
+	path, ok := s.pathOf(params.TextDocument.URI)
+	if !ok {
+		return locations
+	}
+
+	for _, from := range s.index.Paths() {
+		_, links, _ := s.index.Get(from)
+
+		text := ""
+		for _, link := range links {
+			target, _, ok := s.target(link)
+			if !ok || filepath.Clean(string(target)) != filepath.Clean(string(path)) {
+				continue
+			}
+
+			// contents are only read for files that have a reference
+			if text == "" {
+				text, _ = s.text(from)
+			}
+
+			locations = append(locations, Location{URI: s.uriOf(from), Range: rangeOf(text, link.Start, link.End)})
+		}
+	}
+
+	return locations
+}
+
+func (s *server) hover(params TextDocumentPositionParams) *Hover {
+	path, link, ok := s.linkAt(params)
+	if !ok {
+		return nil
+	}
+
+	text := s.documents[path].text
+	hover := func(value string) *Hover {
+		return &Hover{
+			Contents: MarkupContent{Kind: "markdown", Value: value},
+			Range:    rangeOf(text, link.Start, link.End),
+		}
+	}
+
+	if link.IsRemote() {
+		return hover(fmt.Sprintf("Remote link to <%s>", link.Resolved))
+	}
+
+	target, anchor, ok := s.target(link)
+	if !ok {
+		return hover(fmt.Sprintf("Unresolved link to `%s`", link.Url))
+	}
+
+	contents, ok := s.text(target)
+	if !ok {
+		return hover(fmt.Sprintf("`%s` could not be read", target))
+	}
+
+	return hover(fmt.Sprintf("**%s**\n\n---\n\n%s", target, preview(contents, headingOffset(contents, anchor))))
+}
+
+// The first few lines of the text from the offset
+func preview(text string, offset int) string {
+	lines := strings.SplitN(text[offset:], "\n", previewLines+1)
+	if len(lines) > previewLines {
+		lines = append(lines[:previewLines], "…")
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// Completes the url of a link that is being typed, i.e. the text between `](`
+// and the cursor. Paths are written the same way the link fixer writes them
+func (s *server) completion(params TextDocumentPositionParams) []CompletionItem {
+	items := []CompletionItem{}
+
+	path, ok := s.pathOf(params.TextDocument.URI)
+	if !ok {
+		return items
+	}
+
+	doc, ok := s.documents[path]
+	if !ok {
+		return items
+	}
+
+	offset := offsetOf(doc.text, params.Position)
+	lineStart := strings.LastIndexByte(doc.text[:offset], '\n') + 1
+	line := doc.text[lineStart:offset]
+
+	open := strings.LastIndex(line, "](")
+	if open < 0 || strings.ContainsAny(line[open+2:], ") ") {
+		return items
+	}
+
+	start := lineStart + open + 2
+	typed := doc.text[start:offset]
+	edit := rangeOf(doc.text, start, offset)
+
+	if url, _, ok := strings.Cut(typed, "#"); ok {
+		return s.anchorCompletions(path, url, edit)
+	}
+
+	for _, p := range s.index.Paths() {
+		if p == path {
+			continue
+		}
+
+		url := files.LinkTo(s.config, path, p)
+		items = append(items, CompletionItem{
+			Label:    url,
+			Kind:     CompletionItemKindFile,
+			Detail:   string(p),
+			TextEdit: TextEdit{Range: edit, NewText: url},
+		})
+	}
+
+	aliases := s.config.ForFile(string(path)).Aliases
+	for alias, target := range aliases {
+		items = append(items, CompletionItem{
+			Label:    alias,
+			Kind:     CompletionItemKindModule,
+			Detail:   target,
+			TextEdit: TextEdit{Range: edit, NewText: alias},
+		})
+	}
+
+	slices.SortFunc(items, func(a CompletionItem, b CompletionItem) int {
+		return strings.Compare(a.Label, b.Label)
+	})
+
+	return items
+}
+
+// Anchors of the headings in the file that the url points to, or in the file
+// itself if the url is empty
+func (s *server) anchorCompletions(path files.RelativePath, url string, edit Range) []CompletionItem {
+	items := []CompletionItem{}
+
+	target := path
+	if url != "" {
+		resolved, ok := s.resolve(path, url)
+		if !ok {
+			return items
+		}
+
+		target = resolved
+	}
+
+	text, _ := s.text(target)
+	for _, heading := range files.FindHeadings(text) {
+		link := url + "#" + heading.Anchor
+		items = append(items, CompletionItem{
+			Label:    link,
+			Kind:     CompletionItemKindReference,
+			Detail:   heading.Text,
+			TextEdit: TextEdit{Range: edit, NewText: link},
+		})
+	}
+
+	return items
+}
@@ -0,0 +1,356 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+)
+
+const internalError = -32603
+
+// A document that is open in the editor, its text may not have been saved
+type document struct {
+	uri  string
+	text string
+}
+
+type server struct {
+	config config.Config
+	cwd    string
+	conn   *conn
+
+	// created when the client initializes the server
+	index     *files.Index
+	documents map[files.RelativePath]document
+	shutdown  bool
+}
+
+// Serve runs a language server that reads messages from in and writes to out
+// until the client asks it to exit. Open documents are indexed with their
+// unsaved contents so that diagnostics and links are always up to date
+func Serve(c config.Config, in io.Reader, out io.Writer) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	s := &server{
+		config:    c,
+		cwd:       cwd,
+		conn:      newConn(in, out),
+		documents: map[files.RelativePath]document{},
+	}
+
+	return s.serve()
+}
+
+func (s *server) serve() error {
+	for {
+		m, err := s.conn.read()
+
+		var invalid *responseError
+		if errors.As(err, &invalid) {
+			s.reply(nil, nil, invalid)
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if m.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit received before shutdown")
+			}
+
+			return nil
+		}
+
+		result, err := s.handle(m)
+
+		// notifications don't have a response
+		if m.ID == nil {
+			continue
+		}
+
+		if err := s.reply(m.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *server) reply(id *json.RawMessage, result any, err error) error {
+	if err == nil {
+		return s.conn.write(response{Jsonrpc: "2.0", ID: id, Result: result})
+	}
+
+	var re *responseError
+	if !errors.As(err, &re) {
+		re = &responseError{Code: internalError, Message: err.Error()}
+	}
+
+	return s.conn.write(errorResponse{Jsonrpc: "2.0", ID: id, Error: re})
+}
+
+func decode[T any](m message) (T, error) {
+	var params T
+	if err := json.Unmarshal(m.Params, &params); err != nil {
+		return params, &responseError{Code: invalidParams, Message: err.Error()}
+	}
+
+	return params, nil
+}
+
+var capabilities = map[string]any{
+	"textDocumentSync": map[string]any{
+		"openClose": true,
+		// full sync, each change has the whole text of the document
+		"change": 1,
+		"save":   true,
+	},
+	"definitionProvider": true,
+	"referencesProvider": true,
+	"hoverProvider":      true,
+	"completionProvider": map[string]any{
+		"triggerCharacters": []string{"(", "/", "#", "@"},
+	},
+}
+
+func (s *server) handle(m message) (any, error) {
+	if s.shutdown {
+		return nil, &responseError{Code: invalidRequest, Message: "the server is shutting down"}
+	}
+
+	if s.index == nil && m.Method != "initialize" {
+		return nil, &responseError{Code: serverNotInitialized, Message: "the server has not been initialized"}
+	}
+
+	switch m.Method {
+	case "initialize":
+		s.index = files.NewIndex(s.config, files.GetMarkdownFiles(s.config))
+		return map[string]any{
+			"capabilities": capabilities,
+			"serverInfo":   map[string]string{"name": "lynks"},
+		}, nil
+
+	case "initialized":
+		return nil, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		params, err := decode[DidOpenTextDocumentParams](m)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, s.open(params.TextDocument.URI, params.TextDocument.Text)
+
+	case "textDocument/didChange":
+		params, err := decode[DidChangeTextDocumentParams](m)
+		if err != nil || len(params.ContentChanges) == 0 {
+			return nil, err
+		}
+
+		changes := params.ContentChanges
+		return nil, s.open(params.TextDocument.URI, changes[len(changes)-1].Text)
+
+	case "textDocument/didSave":
+		params, err := decode[DidSaveTextDocumentParams](m)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, s.save(params.TextDocument.URI)
+
+	case "textDocument/didClose":
+		params, err := decode[DidCloseTextDocumentParams](m)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, s.close(params.TextDocument.URI)
+
+	case "textDocument/definition":
+		params, err := decode[TextDocumentPositionParams](m)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.definition(params), nil
+
+	case "textDocument/references":
+		params, err := decode[TextDocumentPositionParams](m)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.references(params), nil
+
+	case "textDocument/hover":
+		params, err := decode[TextDocumentPositionParams](m)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.hover(params), nil
+
+	case "textDocument/completion":
+		params, err := decode[TextDocumentPositionParams](m)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.completion(params), nil
+	}
+
+	// unknown notifications, e.g. `$/cancelRequest`, can be ignored
+	if m.ID == nil {
+		return nil, nil
+	}
+
+	return nil, &responseError{Code: methodNotFound, Message: fmt.Sprintf("method %q is not supported", m.Method)}
+}
+
+// Finds the path used by the index for a document
+func (s *server) pathOf(uri string) (files.RelativePath, bool) {
+	abs, ok := uriToPath(uri)
+	if !ok {
+		return "", false
+	}
+
+	// roots given as absolute paths are indexed with absolute paths
+	if _, _, ok := s.index.Get(files.RelativePath(abs)); ok {
+		return files.RelativePath(abs), true
+	}
+
+	rel, err := filepath.Rel(s.cwd, abs)
+	if err != nil {
+		return files.RelativePath(abs), true
+	}
+
+	return files.RelativePath(rel), true
+}
+
+func (s *server) uriOf(path files.RelativePath) string {
+	if doc, ok := s.documents[path]; ok {
+		return doc.uri
+	}
+
+	if filepath.IsAbs(string(path)) {
+		return pathToURI(string(path))
+	}
+
+	return pathToURI(filepath.Join(s.cwd, string(path)))
+}
+
+// The text of a file, using the unsaved text if it's open
+func (s *server) text(path files.RelativePath) (string, bool) {
+	if doc, ok := s.documents[path]; ok {
+		return doc.text, true
+	}
+
+	buf, err := os.ReadFile(string(path))
+	return string(buf), err == nil
+}
+
+func (s *server) parse(path files.RelativePath) {
+	text := s.documents[path].text
+	s.index.Set(files.ParseFiles(s.config, map[files.RelativePath]string{path: text}))
+}
+
+func (s *server) open(uri string, text string) error {
+	path, ok := s.pathOf(uri)
+	if !ok {
+		return nil
+	}
+
+	s.documents[path] = document{uri: uri, text: text}
+	s.parse(path)
+
+	return s.publish(path)
+}
+
+// Saving may create a file that other files link to so they are re-read from
+// disk, open documents keep their unsaved text
+func (s *server) save(uri string) error {
+	path, ok := s.pathOf(uri)
+	if !ok {
+		return nil
+	}
+
+	s.index.Update([]files.RelativePath{path})
+	for open := range s.documents {
+		s.parse(open)
+		if err := s.publish(open); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *server) close(uri string) error {
+	path, ok := s.pathOf(uri)
+	if !ok {
+		return nil
+	}
+
+	delete(s.documents, path)
+	s.index.Update([]files.RelativePath{path})
+
+	return s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: uri, Diagnostics: []Diagnostic{}})
+}
+
+func (s *server) publish(path files.RelativePath) error {
+	return s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         s.documents[path].uri,
+		Diagnostics: s.diagnostics(path),
+	})
+}
+
+var severities = map[config.Severity]int{
+	config.SeverityError: SeverityError,
+	config.SeverityWarn:  SeverityWarning,
+}
+
+// The same problems that lint reports for a file, using the rules for the file
+func (s *server) diagnostics(path files.RelativePath) []Diagnostic {
+	text := s.documents[path].text
+	_, links, _ := s.index.Get(path)
+	rules := s.config.ForFile(string(path)).Rules
+
+	diagnostics := []Diagnostic{}
+	add := func(severity config.Severity, link files.Link, message string) {
+		if severity == config.SeverityOff {
+			return
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    rangeOf(text, link.Start, link.End),
+			Severity: severities[severity],
+			Source:   "lynks",
+			Message:  message,
+		})
+	}
+
+	for _, link := range links {
+		if link.IsUnresolved() {
+			add(rules.UnresolvedLinks, link, fmt.Sprintf("Unresolved link to %s", link.Url))
+		}
+
+		if link.ShouldBeLocal() {
+			add(rules.RemoteShouldBeLocal, link, fmt.Sprintf("Remote link to %s in this repository should be local", link.Resolved))
+		}
+	}
+
+	return diagnostics
+}
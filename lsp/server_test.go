@@ -0,0 +1,397 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/internal/testutil"
+)
+
+// An in-process client that talks to the server over pipes. Messages are read
+// in the background since the server blocks until its notifications are read
+type client struct {
+	t             *testing.T
+	conn          *conn
+	id            int
+	messages      chan message
+	notifications []message
+}
+
+func newClient(t *testing.T, c config.Config) *client {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(c, serverIn, serverOut)
+		serverOut.Close()
+	}()
+
+	cl := &client{t: t, conn: newConn(clientIn, clientOut), messages: make(chan message, 100)}
+	go func() {
+		defer close(cl.messages)
+		for {
+			m, err := cl.conn.read()
+			if err != nil {
+				return
+			}
+
+			cl.messages <- m
+		}
+	}()
+
+	cl.request("initialize", map[string]any{}, nil)
+	cl.notify("initialized", map[string]any{})
+
+	t.Cleanup(func() {
+		cl.request("shutdown", nil, nil)
+		cl.notify("exit", nil)
+
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	})
+
+	return cl
+}
+
+func (c *client) notify(method string, params any) {
+	if err := c.conn.notify(method, params); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) request(method string, params any, result any) {
+	c.id++
+	id := json.RawMessage(strconv.Itoa(c.id))
+
+	content, _ := json.Marshal(params)
+	if err := c.conn.write(message{Jsonrpc: "2.0", ID: &id, Method: method, Params: content}); err != nil {
+		c.t.Fatal(err)
+	}
+
+	for m := range c.messages {
+		if m.ID == nil {
+			c.notifications = append(c.notifications, m)
+			continue
+		}
+
+		if m.Error != nil {
+			c.t.Fatalf("%s failed: %s", method, m.Error.Message)
+		}
+
+		if result != nil {
+			buf, _ := json.Marshal(m.Result)
+			json.Unmarshal(buf, result)
+		}
+
+		return
+	}
+
+	c.t.Fatalf("%s: connection closed", method)
+}
+
+// The most recent diagnostics published for a document
+func (c *client) diagnostics(uri string) []Diagnostic {
+	// a request ensures notifications sent before it have been received
+	c.request("textDocument/hover", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}}, nil)
+
+	for _, m := range slices.Backward(c.notifications) {
+		params := PublishDiagnosticsParams{}
+		json.Unmarshal(m.Params, &params)
+
+		if m.Method == "textDocument/publishDiagnostics" && params.URI == uri {
+			return params.Diagnostics
+		}
+	}
+
+	c.t.Fatalf("no diagnostics published for %s", uri)
+	return nil
+}
+
+func (c *client) open(uri string, text string) {
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{TextDocument: TextDocumentItem{URI: uri, Text: text}})
+}
+
+func uriFor(t *testing.T, name string) string {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pathToURI(abs)
+}
+
+func setup(t *testing.T) *client {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"a.md": "# Intro\n\nSee [usage](b.md#usage), [b](b.md) and [top](#intro)\n",
+		"b.md": "# B\n\n## Usage\n\nRun it\n",
+	})
+
+	c, err := config.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return newClient(t, c)
+}
+
+func at(t *testing.T, name string, line int, character int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uriFor(t, name)},
+		Position:     Position{Line: line, Character: character},
+	}
+}
+
+func TestReplyHasResultOrError(t *testing.T) {
+	type Case struct {
+		err      error
+		expected []string
+	}
+
+	cases := []Case{
+		{nil, []string{"id", "jsonrpc", "result"}},
+		{&responseError{Code: methodNotFound, Message: "nope"}, []string{"error", "id", "jsonrpc"}},
+		{errors.New("failed"), []string{"error", "id", "jsonrpc"}},
+	}
+
+	for _, c := range cases {
+		out := bytes.Buffer{}
+		s := &server{conn: newConn(strings.NewReader(""), &out)}
+
+		id := json.RawMessage("1")
+		if err := s.reply(&id, nil, c.err); err != nil {
+			t.Fatal(err)
+		}
+
+		_, content, _ := strings.Cut(out.String(), "\r\n\r\n")
+
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal([]byte(content), &fields); err != nil {
+			t.Fatal(err)
+		}
+
+		result := slices.Sorted(maps.Keys(fields))
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.err, result, c.expected)
+		}
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	client := setup(t)
+	uri := uriFor(t, "c.md")
+
+	type Case struct {
+		given    string
+		expected []Range
+	}
+
+	cases := []Case{
+		{"[a](a.md)", []Range{}},
+		{"[a](a.md#intro) [top](#top)", []Range{}},
+		{"[a](a.md) [missing](missing.md)", []Range{{Position{0, 10}, Position{0, 31}}}},
+		{"# Ü 😀\n[x](x.md)", []Range{{Position{1, 0}, Position{1, 9}}}},
+	}
+
+	client.open(uri, "")
+	for i, c := range cases {
+		client.notify("textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": uri, "version": i + 1},
+			"contentChanges": []map[string]string{{"text": c.given}},
+		})
+
+		result := []Range{}
+		for _, diagnostic := range client.diagnostics(uri) {
+			result = append(result, diagnostic.Range)
+		}
+
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.given, result, c.expected)
+		}
+	}
+}
+
+func TestSaveResolvesLinks(t *testing.T) {
+	client := setup(t)
+	uri := uriFor(t, "c.md")
+
+	client.open(uri, "[new](new.md)")
+	if diagnostics := client.diagnostics(uri); len(diagnostics) != 1 {
+		t.Fatalf("expected the link to new.md to be unresolved, got %v", diagnostics)
+	}
+
+	testutil.WriteFiles(t, map[string]string{"new.md": "# New"})
+	client.open(uriFor(t, "new.md"), "# New")
+	client.notify("textDocument/didSave", DidSaveTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: uriFor(t, "new.md")}})
+
+	if diagnostics := client.diagnostics(uri); len(diagnostics) != 0 {
+		t.Errorf("expected the link to new.md to resolve once it's saved, got %v", diagnostics)
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	client := setup(t)
+	client.open(uriFor(t, "a.md"), "# Intro\n\nSee [usage](b.md#usage), [b](b.md) and [top](#intro)\n")
+
+	type Case struct {
+		given    Position
+		expected *Location
+	}
+
+	cases := []Case{
+		{Position{2, 5}, &Location{URI: uriFor(t, "b.md"), Range: Range{Position{2, 0}, Position{2, 0}}}},
+		{Position{2, 28}, &Location{URI: uriFor(t, "b.md"), Range: Range{Position{0, 0}, Position{0, 0}}}},
+		{Position{2, 45}, &Location{URI: uriFor(t, "a.md"), Range: Range{Position{0, 0}, Position{0, 0}}}},
+		{Position{2, 0}, nil},
+	}
+
+	for _, c := range cases {
+		var result *Location
+		client.request("textDocument/definition", at(t, "a.md", c.given.Line, c.given.Character), &result)
+
+		if (result == nil) != (c.expected == nil) || (result != nil && *result != *c.expected) {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.given, result, c.expected)
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	client := setup(t)
+
+	result := []Location{}
+	client.request("textDocument/references", at(t, "b.md", 0, 0), &result)
+
+	expected := []Location{
+		{URI: uriFor(t, "a.md"), Range: Range{Position{2, 4}, Position{2, 23}}},
+		{URI: uriFor(t, "a.md"), Range: Range{Position{2, 25}, Position{2, 34}}},
+	}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("\ngiven %v\ngot %v\nexpected %v", "b.md", result, expected)
+	}
+}
+
+func TestHover(t *testing.T) {
+	client := setup(t)
+	client.open(uriFor(t, "a.md"), "# Intro\n\nSee [usage](b.md#usage), [b](b.md) and [top](#intro)\n[x](x.md)")
+
+	type Case struct {
+		given    Position
+		expected string
+	}
+
+	cases := []Case{
+		{Position{2, 5}, "**b.md**\n\n---\n\n## Usage\n\nRun it"},
+		{Position{2, 28}, "**b.md**\n\n---\n\n# B\n\n## Usage\n\nRun it"},
+		{Position{3, 1}, "Unresolved link to `x.md`"},
+	}
+
+	for _, c := range cases {
+		result := Hover{}
+		client.request("textDocument/hover", at(t, "a.md", c.given.Line, c.given.Character), &result)
+
+		if result.Contents.Value != c.expected {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.given, result.Contents.Value, c.expected)
+		}
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	client := setup(t)
+
+	type Case struct {
+		given    string
+		expected []string
+	}
+
+	cases := []Case{
+		{"See [b](", []string{"./a.md", "./b.md"}},
+		{"See [b](b", []string{"./a.md", "./b.md"}},
+		{"See [b](b.md#", []string{"b.md#b", "b.md#usage"}},
+		{"See [b](#", []string{}},
+		{"See [b](b.md) and", []string{}},
+	}
+
+	uri := uriFor(t, "c.md")
+	client.open(uri, "")
+
+	for i, c := range cases {
+		client.notify("textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": uri, "version": i + 1},
+			"contentChanges": []map[string]string{{"text": c.given}},
+		})
+
+		items := []CompletionItem{}
+		client.request("textDocument/completion", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Position:     Position{Line: 0, Character: len(c.given)},
+		}, &items)
+
+		result := []string{}
+		for _, item := range items {
+			result = append(result, item.Label)
+
+			if item.TextEdit.Range.Start.Character != strings.Index(c.given, "](")+2 {
+				t.Errorf("\ngiven %v\ngot edit %v\nexpected it to replace the url", c.given, item.TextEdit.Range)
+			}
+		}
+
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.given, result, c.expected)
+		}
+	}
+}
+
+func TestCompletedLinksResolve(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	testutil.WriteFiles(t, map[string]string{
+		"readme.md":         "# Readme",
+		"docs/a.md":         "",
+		"docs/guide/b.md":   "# B",
+		"docs/guide/sub.md": "# Sub",
+	})
+
+	c, err := config.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := newClient(t, c)
+	uri := uriFor(t, "docs/a.md")
+	client.open(uri, "[x](")
+
+	items := []CompletionItem{}
+	client.request("textDocument/completion", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: 0, Character: 4},
+	}, &items)
+
+	if len(items) != 3 {
+		t.Fatalf("expected a completion for each other file, got %v", items)
+	}
+
+	for i, item := range items {
+		given := "[x](" + item.TextEdit.NewText + ")"
+		client.notify("textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": uri, "version": i + 1},
+			"contentChanges": []map[string]string{{"text": given}},
+		})
+
+		if diagnostics := client.diagnostics(uri); len(diagnostics) != 0 {
+			t.Errorf("\ngiven %v\ngot %v\nexpected no diagnostics", given, diagnostics)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Positions count UTF-16 code units from the start of the line, offsets are
+// bytes from the start of the text
+
+func offsetOf(text string, pos Position) int {
+	offset := 0
+	for range pos.Line {
+		i := strings.IndexByte(text[offset:], '\n')
+		if i < 0 {
+			return len(text)
+		}
+
+		offset += i + 1
+	}
+
+	units := 0
+	for i, r := range text[offset:] {
+		if units >= pos.Character || r == '\n' {
+			return offset + i
+		}
+
+		units += utf16Len(r)
+	}
+
+	return len(text)
+}
+
+func positionOf(text string, offset int) Position {
+	offset = min(offset, len(text))
+	lineStart := strings.LastIndexByte(text[:offset], '\n') + 1
+
+	units := 0
+	for _, r := range text[lineStart:offset] {
+		units += utf16Len(r)
+	}
+
+	return Position{Line: strings.Count(text[:offset], "\n"), Character: units}
+}
+
+func rangeOf(text string, start int, end int) Range {
+	return Range{Start: positionOf(text, start), End: positionOf(text, end)}
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2
+	}
+
+	return 1
+}
+
+func uriToPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+
+	return filepath.FromSlash(u.Path), true
+}
+
+func pathToURI(abs string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+}
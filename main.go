@@ -4,24 +4,10 @@ import (
 	"os"
 
 	"github.com/sftsrv/lynks/cli"
-	"github.com/sftsrv/lynks/config"
-	"github.com/sftsrv/lynks/files"
-	"github.com/sftsrv/lynks/ui"
 )
 
-func main() {
-	configPath := "lynks.config.json"
-	config := config.Load(configPath)
-
-	files := files.GetMarkdownFiles(config)
+//go:generate sh -c "go run . config schema > lynks.schema.json"
 
-	if len(os.Args) < 2 {
-		ui.Run(config, files)
-		return
-	}
-
-	switch os.Args[1] {
-	case "lint":
-		cli.Lint(config, files)
-	}
+func main() {
+	os.Exit(cli.Run(os.Args[1:]))
 }
@@ -27,9 +27,15 @@ func New[I Item]() Model[I] {
 	}
 }
 
+// Items can be replaced at any time, the current search is kept and the cursor
+// stays in place as far as possible
 func (m Model[I]) Items(items []I) Model[I] {
+	cursor := m.cursor
+
 	m.items = items
-	m.filtered = items
+	m = m.applyFilter()
+	m.cursor = clamp(cursor, 0, max(len(m.filtered)-1, 0))
+
 	return m
 }
 
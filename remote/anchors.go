@@ -0,0 +1,132 @@
+package remote
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sftsrv/lynks/files"
+)
+
+// Only the first part of very large pages is checked for anchors
+const maxPageSize = 10 << 20
+
+var idRe = regexp.MustCompile(`(?i)\s(?:id|name)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// Line anchors such as `#L10` or `#L10-L20` are generated by GitHub for any file
+var githubLineRe = regexp.MustCompile(`^L\d+(-L\d+)?$`)
+
+const githubAnchorPrefix = "user-content-"
+
+// Anchors on GitHub are added with javascript so the rendered page can't be
+// used, instead the raw markdown is fetched and the headings are slugified
+func (c *Checker) githubRaw(u *url.URL) (string, bool) {
+	if u.Host != c.githubHost {
+		return "", false
+	}
+
+	// paths look like /org/repo/blob/ref/path/to/file.md
+	parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 4)
+	if len(parts) < 4 || parts[2] != "blob" {
+		return "", false
+	}
+
+	return c.githubRawBase + "/" + parts[0] + "/" + parts[1] + "/" + parts[3], true
+}
+
+func isMarkdown(u *url.URL, contentType string) bool {
+	return strings.HasSuffix(u.Path, ".md") || strings.HasPrefix(contentType, "text/markdown")
+}
+
+// Checks that the page contains an element with the fragment as its id or name,
+// or a heading with a matching slug for markdown pages
+func (c *Checker) checkAnchor(target string, fragment string) (bool, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return false, err
+	}
+
+	raw, isGithub := c.githubRaw(u)
+	if isGithub && githubLineRe.MatchString(fragment) {
+		return true, nil
+	}
+
+	page := target
+	if isGithub {
+		page = raw
+	}
+
+	anchors, err := c.pageAnchors(page)
+	if err != nil {
+		return false, err
+	}
+
+	// the ids in pages rendered by GitHub, e.g. the readme at the root of a
+	// repository, are prefixed with `user-content-`
+	if u.Host == c.githubHost && slices.Contains(anchors, githubAnchorPrefix+fragment) {
+		return true, nil
+	}
+
+	return slices.Contains(anchors, fragment), nil
+}
+
+// Pages are often linked to multiple times with different fragments so the
+// anchors for each page are only fetched once per checker
+func (c *Checker) pageAnchors(page string) ([]string, error) {
+	c.mutex.Lock()
+	anchors, ok := c.anchors[page]
+	c.mutex.Unlock()
+
+	if ok {
+		return anchors, nil
+	}
+
+	u, err := url.Parse(page)
+	if err != nil {
+		return nil, err
+	}
+
+	c.limiter(u.Host).wait(c.interval())
+
+	resp, err := c.client.Get(page)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("failed to fetch %s for anchors: %d %s", page, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if isMarkdown(u, resp.Header.Get("Content-Type")) {
+		anchors = files.Anchors(files.ParseHeadings(string(body)))
+	} else {
+		anchors = htmlAnchors(string(body))
+	}
+
+	c.mutex.Lock()
+	c.anchors[page] = anchors
+	c.mutex.Unlock()
+
+	return anchors, nil
+}
+
+func htmlAnchors(body string) []string {
+	anchors := []string{}
+
+	for _, match := range idRe.FindAllStringSubmatch(body, -1) {
+		anchors = append(anchors, match[1]+match[2]+match[3])
+	}
+
+	return anchors
+}
@@ -0,0 +1,301 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sftsrv/lynks/config"
+)
+
+type Result struct {
+	Url        string
+	StatusCode int
+	// The final url if the request was redirected
+	Redirect string
+	// Set if the url has a fragment that does not exist on the page
+	MissingAnchor string
+	Error         string
+	CheckedAt     time.Time
+}
+
+func (r Result) IsBroken() bool {
+	return r.Error != "" || r.StatusCode >= 400 || r.MissingAnchor != ""
+}
+
+func (r Result) IsRedirected() bool {
+	return r.Redirect != ""
+}
+
+func (r Result) Title() string {
+	if r.Error != "" {
+		return r.Error
+	}
+
+	if r.MissingAnchor != "" {
+		return fmt.Sprintf("anchor #%s not found", r.MissingAnchor)
+	}
+
+	if r.IsRedirected() {
+		return fmt.Sprintf("%d redirected to %s", r.StatusCode, r.Redirect)
+	}
+
+	return fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode))
+}
+
+// Hosts limit how quickly we may send requests so each host gets its own slot
+type limiter struct {
+	mutex sync.Mutex
+	next  time.Time
+}
+
+func (l *limiter) wait(interval time.Duration) {
+	l.mutex.Lock()
+	now := time.Now()
+	start := now
+	if l.next.After(now) {
+		start = l.next
+	}
+
+	l.next = start.Add(interval)
+	l.mutex.Unlock()
+
+	time.Sleep(start.Sub(now))
+}
+
+type Checker struct {
+	config    config.Remote
+	client    *http.Client
+	cacheFile string
+	// delay before the first retry, doubled for each subsequent retry
+	backoff time.Duration
+
+	githubHost    string
+	githubRawBase string
+
+	mutex    sync.Mutex
+	limiters map[string]*limiter
+	cache    map[string]Result
+	anchors  map[string][]string
+}
+
+// NewChecker creates a checker that keeps results in the given cache file. An
+// empty cacheFile disables the cache
+func NewChecker(config config.Remote, cacheFile string) *Checker {
+	c := &Checker{
+		config:    config,
+		client:    &http.Client{Timeout: time.Duration(config.Timeout)},
+		cacheFile: cacheFile,
+		backoff:   500 * time.Millisecond,
+
+		githubHost:    "github.com",
+		githubRawBase: "https://raw.githubusercontent.com",
+
+		limiters: map[string]*limiter{},
+		cache:    map[string]Result{},
+		anchors:  map[string][]string{},
+	}
+
+	c.loadCache()
+
+	return c
+}
+
+func (c *Checker) loadCache() {
+	if c.cacheFile == "" {
+		return
+	}
+
+	data, err := os.ReadFile(c.cacheFile)
+	if err != nil {
+		return
+	}
+
+	// an invalid cache is the same as an empty one
+	json.Unmarshal(data, &c.cache)
+}
+
+func (c *Checker) saveCache() error {
+	if c.cacheFile == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(c.cache)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(c.cacheFile), 0o755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.cacheFile, data, 0o644)
+}
+
+func (c *Checker) cached(u string) (Result, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result, ok := c.cache[u]
+	if !ok || time.Since(result.CheckedAt) > time.Duration(c.config.CacheTTL) {
+		return Result{}, false
+	}
+
+	return result, true
+}
+
+func (c *Checker) limiter(host string) *limiter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	l, ok := c.limiters[host]
+	if !ok {
+		l = &limiter{}
+		c.limiters[host] = l
+	}
+
+	return l
+}
+
+// Check requests each of the given urls and returns the results keyed by url.
+// Duplicate urls are only requested once
+func (c *Checker) Check(urls []string) map[string]Result {
+	unique := map[string]bool{}
+	for _, u := range urls {
+		unique[u] = true
+	}
+
+	jobs := make(chan string)
+	results := map[string]Result{}
+	resultsMutex := sync.Mutex{}
+	wg := sync.WaitGroup{}
+
+	for range max(c.config.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for u := range jobs {
+				result := c.check(u)
+
+				resultsMutex.Lock()
+				results[u] = result
+				resultsMutex.Unlock()
+			}
+		}()
+	}
+
+	for u := range unique {
+		jobs <- u
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// failures that would have been retried may be temporary, e.g. an outage,
+	// so they are checked again next time rather than reported until they expire
+	for u, result := range results {
+		if !isRetryable(result) {
+			c.cache[u] = result
+		}
+	}
+
+	// if the results can't be saved each remote link is requested again on the
+	// next run, which is slow but still correct
+	c.saveCache()
+
+	return results
+}
+
+func (c *Checker) check(u string) Result {
+	if result, ok := c.cached(u); ok {
+		return result
+	}
+
+	// fragments are never sent to the server
+	target, fragment, _ := strings.Cut(u, "#")
+
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return Result{Url: u, Error: err.Error(), CheckedAt: time.Now()}
+	}
+
+	result := Result{}
+	backoff := c.backoff
+	for attempt := range c.config.Retries + 1 {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		c.limiter(parsed.Host).wait(c.interval())
+		result = c.request(target)
+		result.Url = u
+
+		if !isRetryable(result) {
+			break
+		}
+	}
+
+	if fragment == "" || result.IsBroken() {
+		return result
+	}
+
+	found, err := c.checkAnchor(target, fragment)
+	if err != nil {
+		result.Error = err.Error()
+	} else if !found {
+		result.MissingAnchor = fragment
+	}
+
+	return result
+}
+
+// The time to wait between requests to the same host
+func (c *Checker) interval() time.Duration {
+	if c.config.RequestsPerSecond <= 0 {
+		return 0
+	}
+
+	return time.Duration(float64(time.Second) / c.config.RequestsPerSecond)
+}
+
+// Requests that failed due to the network or the server may succeed if tried again
+func isRetryable(result Result) bool {
+	return result.Error != "" || result.StatusCode == http.StatusTooManyRequests || result.StatusCode >= 500
+}
+
+func (c *Checker) request(target string) Result {
+	resp, err := c.client.Head(target)
+
+	// some servers don't support HEAD requests so fall back to GET
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = c.client.Get(target)
+	}
+
+	if err != nil {
+		return Result{Error: err.Error(), CheckedAt: time.Now()}
+	}
+
+	defer resp.Body.Close()
+
+	result := Result{StatusCode: resp.StatusCode, CheckedAt: time.Now()}
+
+	final := resp.Request.URL.String()
+	if final != target {
+		result.Redirect = final
+	}
+
+	return result
+}
@@ -0,0 +1,228 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sftsrv/lynks/config"
+)
+
+func testConfig() config.Remote {
+	return config.Remote{
+		Concurrency: 4,
+		Timeout:     config.Duration(time.Second),
+		Retries:     2,
+		CacheTTL:    config.Duration(time.Hour),
+	}
+}
+
+func testServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	requests := &atomic.Int32{}
+	flaky := &atomic.Int32{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	})
+
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+	})
+
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if flaky.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, requests
+}
+
+func TestCheck(t *testing.T) {
+	server, _ := testServer(t)
+
+	checker := NewChecker(testConfig(), "")
+	checker.backoff = time.Millisecond
+
+	type Case struct {
+		path       string
+		broken     bool
+		redirected bool
+	}
+
+	cases := []Case{
+		{"/ok", false, false},
+		// the page has no anchors so the fragment can't be found
+		{"/ok#some-heading", true, false},
+		{"/missing", true, false},
+		{"/moved", false, true},
+		{"/get-only", false, false},
+		{"/flaky", false, false},
+	}
+
+	urls := []string{}
+	for _, c := range cases {
+		urls = append(urls, server.URL+c.path)
+	}
+
+	results := checker.Check(urls)
+
+	for _, c := range cases {
+		result := results[server.URL+c.path]
+		if result.IsBroken() != c.broken || result.IsRedirected() != c.redirected {
+			t.Errorf("\ngiven %v\ngot %v", c, result)
+		}
+	}
+}
+
+func TestCheckUsesCache(t *testing.T) {
+	server, requests := testServer(t)
+	cacheFile := filepath.Join(t.TempDir(), "remote.json")
+
+	NewChecker(testConfig(), cacheFile).Check([]string{server.URL + "/ok"})
+	NewChecker(testConfig(), cacheFile).Check([]string{server.URL + "/ok"})
+
+	if requests.Load() != 1 {
+		t.Errorf("expected a single request with the cache, got %d", requests.Load())
+	}
+
+	expired := testConfig()
+	expired.CacheTTL = 0
+
+	NewChecker(expired, cacheFile).Check([]string{server.URL + "/ok"})
+	if requests.Load() != 2 {
+		t.Errorf("expected expired results to be requested again, got %d", requests.Load())
+	}
+}
+
+func TestCheckDoesNotCacheTemporaryFailures(t *testing.T) {
+	server, requests := testServer(t)
+	cacheFile := filepath.Join(t.TempDir(), "remote.json")
+
+	config := testConfig()
+	config.Retries = 0
+
+	first := NewChecker(config, cacheFile).Check([]string{server.URL + "/flaky", server.URL + "/missing"})
+	if !first[server.URL+"/flaky"].IsBroken() {
+		t.Fatalf("expected the first request to fail, got %v", first[server.URL+"/flaky"])
+	}
+
+	second := NewChecker(config, cacheFile).Check([]string{server.URL + "/flaky", server.URL + "/missing"})
+	if second[server.URL+"/flaky"].IsBroken() {
+		t.Errorf("expected the failure to be checked again, got %v", second[server.URL+"/flaky"])
+	}
+
+	// the missing page is only requested once since a 404 is cached
+	if requests.Load() != 3 {
+		t.Errorf("expected 3 requests, got %d", requests.Load())
+	}
+}
+
+func TestCheckRateLimitsPerHost(t *testing.T) {
+	server, _ := testServer(t)
+
+	config := testConfig()
+	config.RequestsPerSecond = 20
+
+	checker := NewChecker(config, "")
+
+	start := time.Now()
+	checker.Check([]string{server.URL + "/ok", server.URL + "/missing", server.URL + "/get-only"})
+
+	// three requests to the same host need at least two intervals between them
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected requests to be rate limited, took %v", elapsed)
+	}
+}
+
+func TestCheckAnchors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<h1 id="intro">Intro</h1><a name='legacy'></a><div id=bare></div>`))
+	})
+
+	mux.HandleFunc("/doc.md", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# Doc\n\n## Getting Started\n"))
+	})
+
+	mux.HandleFunc("/org/repo/blob/main/docs/readme.md", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<div id="user-content-usage"></div>`))
+	})
+
+	mux.HandleFunc("/org/repo", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<h2 id="user-content-installation">Installation</h2><div id="repo-content"></div>`))
+	})
+
+	mux.HandleFunc("/raw/org/repo/main/docs/readme.md", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# Readme\n\n## Usage\n\n## Usage\n"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	checker := NewChecker(testConfig(), "")
+	checker.githubHost = server.Listener.Addr().String()
+	checker.githubRawBase = server.URL + "/raw"
+
+	type Case struct {
+		url    string
+		broken bool
+	}
+
+	blob := server.URL + "/org/repo/blob/main/docs/readme.md"
+	cases := []Case{
+		{server.URL + "/page#intro", false},
+		{server.URL + "/page#legacy", false},
+		{server.URL + "/page#bare", false},
+		{server.URL + "/page#missing", true},
+		{server.URL + "/doc.md#getting-started", false},
+		{server.URL + "/doc.md#missing", true},
+		{blob + "#usage", false},
+		{blob + "#usage-1", false},
+		{blob + "#L10-L20", false},
+		{blob + "#installation", true},
+		{server.URL + "/org/repo#installation", false},
+		{server.URL + "/org/repo#repo-content", false},
+		{server.URL + "/org/repo#usage", true},
+	}
+
+	urls := []string{}
+	for _, c := range cases {
+		urls = append(urls, c.url)
+	}
+
+	results := checker.Check(urls)
+
+	for _, c := range cases {
+		result := results[c.url]
+		if result.IsBroken() != c.broken {
+			t.Errorf("\ngiven %v\ngot %v", c, result)
+		}
+	}
+}
@@ -18,4 +18,5 @@ var Primary = lg.NewStyle().Foreground(ColorPrimary)
 var Faded = lg.NewStyle().Foreground(ColorFaded)
 var Active = lg.NewStyle().Foreground(ColorPrimary)
 var Warn = lg.NewStyle().Foreground(ColorWarn)
+var Error = lg.NewStyle().Foreground(ColorError)
 var Alert = lg.NewStyle().Bold(true).PaddingLeft(1).PaddingRight(1).Background(ColorError)
@@ -1,9 +1,6 @@
 package ui
 
 import (
-	"fmt"
-	"os"
-
 	tea "github.com/charmbracelet/bubbletea"
 	lg "github.com/charmbracelet/lipgloss"
 	"github.com/sftsrv/lynks/config"
@@ -26,21 +23,65 @@ const (
 )
 
 type Model struct {
-	config config.Config
+	config  config.Config
+	index   *paths.Index
+	watcher *paths.Watcher
 
 	state  state
 	window window
 
 	file       paths.File
-	filepicker picker.Model[paths.RelativePath]
+	filepicker picker.Model[paths.File]
 
 	link       paths.Link
 	linkpicker picker.Model[paths.Link]
 	linkfixer  picker.Model[paths.RelativePath]
 }
 
+type changesMsg []paths.RelativePath
+
+type watchErrorMsg struct {
+	err error
+}
+
 func (m Model) Init() tea.Cmd {
-	return nil
+	return m.waitForChanges()
+}
+
+func (m Model) waitForChanges() tea.Cmd {
+	if m.watcher == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		select {
+		case changes, ok := <-m.watcher.Changes:
+			if !ok {
+				return nil
+			}
+
+			return changesMsg(changes)
+
+		case err := <-m.watcher.Errors:
+			return watchErrorMsg{err}
+		}
+	}
+}
+
+// Refreshes all views from the index, e.g. after files have changed on disk
+func (m Model) refresh() Model {
+	m.filepicker = m.filepicker.Items(m.index.Files())
+	m.linkfixer = m.linkfixer.Items(m.index.Paths())
+
+	file, links, ok := m.index.Get(m.file.Path)
+	if !ok {
+		m.state = filePickerView
+		return m
+	}
+
+	m.file = file
+	m.linkpicker = m.linkpicker.Items(links)
+	return m
 }
 
 func (w *window) updateWindowSize(width int, height int) {
@@ -57,25 +98,37 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.linkfixer = m.linkfixer.Height(msg.Height - 3)
 		return m, nil
 
-	case picker.SelectedMsg[paths.RelativePath]:
-		switch m.state {
-		case filePickerView:
-			file, links := paths.ReadFile(m.config, msg.Selected)
+	case changesMsg:
+		m.index.Update(msg)
+		return m.refresh(), m.waitForChanges()
 
-			m.state = linkPickerView
-			m.file = file
-			m.linkpicker = m.linkpicker.Items(links)
+	case watchErrorMsg:
+		// the view is still usable without watching so keep going
+		return m, m.waitForChanges()
 
-		case linkFixerView:
-			m.state = linkPickerView
-			updated := paths.FixLink(m.config, m.file, m.link, msg.Selected)
-			paths.UpdateFile(m.config.Resolution, updated)
-			file, links := paths.ReadFile(m.config, updated.Path)
+	case picker.SelectedMsg[paths.File]:
+		_, links, _ := m.index.Get(msg.Selected.Path)
+
+		m.state = linkPickerView
+		m.file = msg.Selected
+		m.linkpicker = m.linkpicker.Items(links)
 
-			m.file = file
-			m.linkpicker = m.linkpicker.Items(links)
+	case picker.SelectedMsg[paths.RelativePath]:
+		m.state = linkPickerView
+		// the index may not have the latest contents of the file
+		current, _, err := paths.ReadFile(m.config, m.file.Path)
+		if err != nil {
+			// the file was removed or can't be read so there is nothing to fix
+			m.index.Update([]paths.RelativePath{m.file.Path})
+			return m.refresh(), nil
 		}
 
+		updated := paths.FixLink(m.config, current, m.link, msg.Selected)
+		paths.UpdateFile(m.config.Resolution, updated)
+		m.index.Update([]paths.RelativePath{updated.Path})
+
+		return m.refresh(), nil
+
 	case picker.SelectedMsg[paths.Link]:
 		m.state = linkFixerView
 
@@ -170,23 +223,31 @@ func (m Model) View() string {
 	return "unexpected state"
 }
 
-func initialModel(config config.Config, f []paths.RelativePath) Model {
+func initialModel(config config.Config, index *paths.Index, watcher *paths.Watcher) Model {
 	return Model{
 		config:     config,
+		index:      index,
+		watcher:    watcher,
 		state:      filePickerView,
-		filepicker: picker.New[paths.RelativePath]().Title("File to check").Accent(theme.ColorPrimary).Items(f),
+		filepicker: picker.New[paths.File]().Title("File to check").Accent(theme.ColorPrimary).Items(index.Files()),
 		linkpicker: picker.New[paths.Link]().Title("Edit Link").Accent(theme.ColorSecondary),
-		linkfixer:  picker.New[paths.RelativePath]().Title("Fix link").Accent(theme.ColorSecondary).Items(f),
+		linkfixer:  picker.New[paths.RelativePath]().Title("Fix link").Accent(theme.ColorSecondary).Items(index.Paths()),
 	}
 }
 
-func Run(config config.Config, f []paths.RelativePath) {
-	m := initialModel(config, f)
+func Run(config config.Config, f []paths.RelativePath) error {
+	index := paths.NewIndex(config, f)
+
+	// the ui still works without live updates if the root can't be watched
+	watcher, err := paths.NewWatcher(config)
+	if err == nil {
+		defer watcher.Close()
+	}
+
+	m := initialModel(config, index, watcher)
 
 	p := tea.NewProgram(m)
 
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Alas, there's been an error: %v", err)
-		os.Exit(1)
-	}
+	_, err = p.Run()
+	return err
 }
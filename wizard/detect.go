@@ -0,0 +1,181 @@
+package wizard
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+)
+
+// A root is suggested if it contains at least this share of all markdown files
+const rootThreshold = 0.8
+
+// Directories that never contain docs that should be checked
+func isSkipped(p string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(p), "/") {
+		if part == "node_modules" || (strings.HasPrefix(part, ".") && part != "." && part != "..") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func markdownFiles() []files.RelativePath {
+	defaults, _ := config.Load("")
+
+	found := []files.RelativePath{}
+	for _, path := range files.GetMarkdownFiles(defaults) {
+		if !isSkipped(string(path)) {
+			found = append(found, path)
+		}
+	}
+
+	return found
+}
+
+type rootCandidate struct {
+	dir   string
+	count int
+}
+
+// Counts the markdown files within each directory, including subdirectories,
+// and orders them with the suggested root first. The suggested root is the
+// deepest directory that contains most of the markdown files
+func rootCandidates(paths []files.RelativePath) []rootCandidate {
+	counts := map[string]int{}
+
+	for _, path := range paths {
+		dir := filepath.Dir(string(path))
+		for {
+			counts[dir]++
+
+			if dir == "." || dir == "/" {
+				break
+			}
+
+			dir = filepath.Dir(dir)
+		}
+	}
+
+	candidates := []rootCandidate{}
+	for dir, count := range counts {
+		candidates = append(candidates, rootCandidate{dir, count})
+	}
+
+	depth := func(dir string) int {
+		if dir == "." {
+			return 0
+		}
+
+		return strings.Count(dir, string(filepath.Separator)) + 1
+	}
+
+	threshold := int(float64(len(paths)) * rootThreshold)
+
+	slices.SortFunc(candidates, func(a rootCandidate, b rootCandidate) int {
+		aSuggested := a.count >= threshold
+		bSuggested := b.count >= threshold
+
+		if aSuggested != bSuggested {
+			if aSuggested {
+				return -1
+			}
+
+			return 1
+		}
+
+		if aSuggested && depth(a.dir) != depth(b.dir) {
+			return depth(b.dir) - depth(a.dir)
+		}
+
+		if a.count != b.count {
+			return b.count - a.count
+		}
+
+		return strings.Compare(a.dir, b.dir)
+	})
+
+	return candidates
+}
+
+type linkStyle struct {
+	strategy      config.ResolutionStrategy
+	keepExtension bool
+}
+
+func isLocalUrl(url string) bool {
+	return !strings.Contains(url, "://") && !strings.HasPrefix(url, "#") && !strings.HasPrefix(url, "mailto:")
+}
+
+// Detects whether most existing local links are relative or from the root and
+// whether they include the extension
+func detectLinkStyle(urls []string) linkStyle {
+	relative := 0
+	root := 0
+	withExtension := 0
+	withoutExtension := 0
+
+	for _, url := range urls {
+		if !isLocalUrl(url) {
+			continue
+		}
+
+		if strings.HasPrefix(url, "./") || strings.HasPrefix(url, "../") {
+			relative++
+		} else {
+			root++
+		}
+
+		url, _, _ = strings.Cut(url, "#")
+		if strings.HasSuffix(url, ".md") {
+			withExtension++
+		} else {
+			withoutExtension++
+		}
+	}
+
+	style := linkStyle{
+		strategy:      config.RelativeResolutionStrategy,
+		keepExtension: withExtension >= withoutExtension,
+	}
+
+	if root > relative {
+		style.strategy = config.RootResolutionStrategy
+	}
+
+	return style
+}
+
+// Links that start with a prefix such as `@api/` or `~docs/` are likely to be
+// using an alias, these are matched with a directory of the same name
+func detectAliases(urls []string, dirs []string) map[string]string {
+	aliases := map[string]string{}
+
+	for _, url := range urls {
+		if !isLocalUrl(url) || (!strings.HasPrefix(url, "@") && !strings.HasPrefix(url, "~")) {
+			continue
+		}
+
+		prefix, _, found := strings.Cut(url, "/")
+		if !found {
+			continue
+		}
+
+		if _, ok := aliases[prefix]; ok {
+			continue
+		}
+
+		name := strings.TrimLeft(prefix, "@~")
+		for _, dir := range dirs {
+			if filepath.Base(dir) == name {
+				aliases[prefix] = dir
+				break
+			}
+		}
+	}
+
+	return aliases
+}
@@ -0,0 +1,306 @@
+package wizard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	lg "github.com/charmbracelet/lipgloss"
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+	"github.com/sftsrv/lynks/picker"
+	"github.com/sftsrv/lynks/theme"
+)
+
+const configFile = "lynks.config.json"
+const schemaUrl = "https://raw.githubusercontent.com/sftsrv/lynks/main/lynks.schema.json"
+
+type option struct {
+	label string
+	value string
+}
+
+func (o option) Title() string {
+	return o.label
+}
+
+type step int
+
+const (
+	rootStep step = iota
+	strategyStep
+	extensionStep
+	aliasesStep
+	doneStep
+)
+
+type resolution struct {
+	Strategy      config.ResolutionStrategy `json:"strategy"`
+	KeepExtension bool                      `json:"keepExtension"`
+}
+
+// Only the values chosen in the wizard are written, everything else uses the
+// defaults
+type initialConfig struct {
+	Schema     string            `json:"$schema"`
+	Root       string            `json:"root"`
+	Resolution resolution        `json:"resolution"`
+	Aliases    map[string]string `json:"aliases,omitempty"`
+}
+
+type Model struct {
+	step   step
+	picker picker.Model[option]
+	height int
+
+	candidates []rootCandidate
+	style      linkStyle
+	aliases    map[string]string
+
+	config initialConfig
+	err    error
+}
+
+// Paths in the config start with `./` to make it clear that they are relative
+func configPath(p string) string {
+	p = filepath.ToSlash(filepath.Clean(p))
+	if p == "." {
+		return "./"
+	}
+
+	return "./" + p
+}
+
+func yesNo(label string, yes bool) []option {
+	options := []option{{label + ": yes", "yes"}, {label + ": no", "no"}}
+	if !yes {
+		slices.Reverse(options)
+	}
+
+	return options
+}
+
+func (m Model) options() (string, []option) {
+	switch m.step {
+	case rootStep:
+		options := []option{}
+		for _, candidate := range m.candidates {
+			options = append(options, option{fmt.Sprintf("%s (%d markdown files)", configPath(candidate.dir), candidate.count), candidate.dir})
+		}
+
+		return "Root folder", options
+
+	case strategyStep:
+		root := option{"root: links are relative to the root folder", string(config.RootResolutionStrategy)}
+		relative := option{"relative: links are relative to the file they are in", string(config.RelativeResolutionStrategy)}
+
+		if m.style.strategy == config.RootResolutionStrategy {
+			return "Resolution strategy", []option{root, relative}
+		}
+
+		return "Resolution strategy", []option{relative, root}
+
+	case extensionStep:
+		return "Keep extension", yesNo("Keep `.md` in links", m.style.keepExtension)
+
+	case aliasesStep:
+		suggested := []string{}
+		for _, key := range slices.Sorted(maps.Keys(m.aliases)) {
+			suggested = append(suggested, key+" → "+m.aliases[key])
+		}
+
+		return "Aliases", []option{
+			{"Use " + strings.Join(suggested, ", "), "yes"},
+			{"No aliases", "no"},
+		}
+	}
+
+	return "", nil
+}
+
+// Moves to the next step, skipping the aliases if none were found
+func (m Model) next() Model {
+	m.step++
+	if m.step == aliasesStep && len(m.aliases) == 0 {
+		m.step++
+	}
+
+	title, options := m.options()
+	m.picker = picker.New[option]().Title(title).Accent(theme.ColorPrimary).Items(options).Height(m.height)
+
+	return m
+}
+
+// Alias targets are resolved from the root so the detected directories need to
+// be made relative to it
+func rootAliases(root string, detected map[string]string) map[string]string {
+	aliases := map[string]string{}
+
+	for prefix, dir := range detected {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		aliases[prefix] = configPath(rel)
+	}
+
+	return aliases
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = max(msg.Height-6, 2)
+		m.picker = m.picker.Height(m.height)
+		return m, nil
+
+	case picker.SelectedMsg[option]:
+		switch m.step {
+		case rootStep:
+			m.config.Root = configPath(msg.Selected.value)
+			m.aliases = rootAliases(msg.Selected.value, m.aliases)
+
+		case strategyStep:
+			m.config.Resolution.Strategy = config.ResolutionStrategy(msg.Selected.value)
+
+		case extensionStep:
+			m.config.Resolution.KeepExtension = msg.Selected.value == "yes"
+
+		case aliasesStep:
+			if msg.Selected.value == "yes" {
+				m.config.Aliases = m.aliases
+			}
+		}
+
+		m = m.next()
+		if m.step == doneStep {
+			m.err = write(m.config)
+			return m, tea.Quit
+		}
+
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+
+		var cmd tea.Cmd
+		m.picker, cmd = m.picker.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m Model) summary() string {
+	lines := []string{}
+
+	if m.step > rootStep {
+		lines = append(lines, theme.Faded.Render("root: ")+theme.Primary.Render(m.config.Root))
+	}
+
+	if m.step > strategyStep {
+		lines = append(lines, theme.Faded.Render("strategy: ")+theme.Primary.Render(string(m.config.Resolution.Strategy)))
+	}
+
+	if m.step > extensionStep {
+		lines = append(lines, theme.Faded.Render("keepExtension: ")+theme.Primary.Render(fmt.Sprint(m.config.Resolution.KeepExtension)))
+	}
+
+	return lg.JoinVertical(lg.Top, lines...)
+}
+
+func (m Model) View() string {
+	header := theme.Heading.Render("Create config") + theme.Primary.MarginLeft(1).Render(configFile)
+
+	if m.step == doneStep {
+		return lg.JoinVertical(lg.Top, header, m.summary(), "")
+	}
+
+	return lg.JoinVertical(lg.Top, header, m.summary(), m.picker.View())
+}
+
+func write(c initialConfig) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(configFile, append(data, '\n'), 0644)
+}
+
+func initialModel(paths []files.RelativePath) Model {
+	candidates := rootCandidates(paths)
+
+	urls := []string{}
+	for _, parsed := range files.ReadFiles(config.Config{Dir: "."}, paths) {
+		for _, link := range parsed.Links {
+			urls = append(urls, link.Url)
+		}
+	}
+
+	dirs := []string{}
+	for _, candidate := range candidates {
+		dirs = append(dirs, candidate.dir)
+	}
+
+	m := Model{
+		step:       rootStep,
+		height:     5,
+		candidates: candidates,
+		style:      detectLinkStyle(urls),
+		aliases:    detectAliases(urls, dirs),
+		config:     initialConfig{Schema: schemaUrl},
+	}
+
+	title, options := m.options()
+	m.picker = picker.New[option]().Title(title).Accent(theme.ColorPrimary).Items(options)
+
+	return m
+}
+
+// Run scans the current directory and asks a few questions in order to create
+// a config that matches how the docs are already written
+func Run() error {
+	existing, err := config.Find(".")
+	if err != nil {
+		return err
+	}
+
+	if existing != "" {
+		return fmt.Errorf("config already exists at %s", existing)
+	}
+
+	paths := markdownFiles()
+	if len(paths) == 0 {
+		return errors.New("no markdown files found")
+	}
+
+	result, err := tea.NewProgram(initialModel(paths)).Run()
+	if err != nil {
+		return err
+	}
+
+	m := result.(Model)
+	if m.err != nil {
+		return fmt.Errorf("could not write config: %w", m.err)
+	}
+
+	if m.step == doneStep {
+		fmt.Println(theme.Heading.Render("Config created") + theme.Primary.MarginLeft(1).Render(configFile))
+	}
+
+	return nil
+}
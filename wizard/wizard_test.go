@@ -0,0 +1,122 @@
+package wizard
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/sftsrv/lynks/config"
+	"github.com/sftsrv/lynks/files"
+	"github.com/sftsrv/lynks/picker"
+)
+
+func TestRootCandidates(t *testing.T) {
+	type Case struct {
+		paths    []files.RelativePath
+		expected string
+	}
+
+	cases := []Case{
+		{[]files.RelativePath{"README.md", "docs/a.md", "docs/b.md", "docs/c/d.md", "docs/c/e.md"}, "docs"},
+		{[]files.RelativePath{"README.md", "CHANGELOG.md", "docs/a.md"}, "."},
+		{[]files.RelativePath{"src/docs/a.md", "src/docs/b.md"}, "src/docs"},
+		{[]files.RelativePath{"docs/a.md", "docs/b.md", "blog/a.md", "blog/b.md"}, "."},
+	}
+
+	for _, c := range cases {
+		result := rootCandidates(c.paths)[0].dir
+		if result != c.expected {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.paths, result, c.expected)
+		}
+	}
+}
+
+func TestDetectLinkStyle(t *testing.T) {
+	type Case struct {
+		urls     []string
+		expected linkStyle
+	}
+
+	cases := []Case{
+		{[]string{"./a.md", "../b.md", "c/d"}, linkStyle{config.RelativeResolutionStrategy, true}},
+		{[]string{"a/b", "c/d#heading", "./e"}, linkStyle{config.RootResolutionStrategy, false}},
+		{[]string{"https://example.com/a.md", "#heading", "./a"}, linkStyle{config.RelativeResolutionStrategy, false}},
+	}
+
+	for _, c := range cases {
+		result := detectLinkStyle(c.urls)
+		if result != c.expected {
+			t.Errorf("\ngiven %v\ngot %v\nexpected %v", c.urls, result, c.expected)
+		}
+	}
+}
+
+func TestDetectAliases(t *testing.T) {
+	urls := []string{"@api/users", "~guides/setup.md", "@missing/page", "./@api/local", "@api"}
+	dirs := []string{".", "docs", "docs/generated/api", "docs/guides"}
+
+	expected := map[string]string{
+		"@api":    "docs/generated/api",
+		"~guides": "docs/guides",
+	}
+
+	result := detectAliases(urls, dirs)
+	if !maps.Equal(result, expected) {
+		t.Errorf("\ngiven %v\ngot %v\nexpected %v", urls, result, expected)
+	}
+
+	relative := rootAliases("docs", result)
+	expected = map[string]string{
+		"@api":    "./generated/api",
+		"~guides": "./guides",
+	}
+
+	if !maps.Equal(relative, expected) {
+		t.Errorf("\ngiven %v\ngot %v\nexpected %v", result, relative, expected)
+	}
+}
+
+func TestWizardWritesConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	m := Model{
+		step:       rootStep,
+		candidates: []rootCandidate{{"docs", 2}},
+		style:      linkStyle{config.RootResolutionStrategy, false},
+		aliases:    map[string]string{"@api": "docs/api"},
+		config:     initialConfig{Schema: schemaUrl},
+	}
+
+	// accept the suggested option for each step
+	for m.step != doneStep {
+		_, options := m.options()
+
+		result, _ := m.Update(picker.SelectedMsg[option]{Selected: options[0]})
+		m = result.(Model)
+	}
+
+	if m.err != nil {
+		t.Fatal(m.err)
+	}
+
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := initialConfig{}
+	json.Unmarshal(data, &result)
+
+	expected := initialConfig{
+		Schema:     schemaUrl,
+		Root:       "./docs",
+		Resolution: resolution{config.RootResolutionStrategy, false},
+		Aliases:    map[string]string{"@api": "./api"},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("\ngot %v\nexpected %v", result, expected)
+	}
+}